@@ -0,0 +1,232 @@
+package iso8583
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/pentaly7/iso8583/framing"
+)
+
+var (
+	ErrClientClosed        = errors.New("client closed")
+	ErrDuplicateMessageKey = errors.New("request with the same message key is in flight")
+)
+
+// Client multiplexes requests over a single connection.
+// Responses are matched to their request with GetMessageKey.
+type Client struct {
+	onUnmatched func(msg *Message)
+	onError     func(err error)
+
+	conn     io.ReadWriteCloser
+	packager *IsoPackager
+	codec    *framing.Codec
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	pending map[string]chan *Message
+
+	done      chan struct{}
+	closeOnce sync.Once
+	err       error
+}
+
+// ClientOption configures a Client created by NewClient or Dial
+type ClientOption func(c *Client)
+
+// WithUnmatchedHandler sets the function called from the read loop for every message
+// that does not match an in-flight request, e.g. late responses after a timeout
+func WithUnmatchedHandler(fn func(msg *Message)) ClientOption {
+	return func(c *Client) {
+		c.onUnmatched = fn
+	}
+}
+
+// WithErrorHandler sets the function called from the read loop for frames that cannot be unpacked
+func WithErrorHandler(fn func(err error)) ClientOption {
+	return func(c *Client) {
+		c.onError = fn
+	}
+}
+
+// Dial connects to address and creates a client on the connection
+func Dial(ctx context.Context, network, address string, packager *IsoPackager, codec *framing.Codec, opts ...ClientOption) (*Client, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(conn, packager, codec, opts...), nil
+}
+
+// NewClient creates a client on conn and starts reading responses
+func NewClient(conn io.ReadWriteCloser, packager *IsoPackager, codec *framing.Codec, opts ...ClientOption) *Client {
+	c := newClient(conn, packager, codec, opts...)
+	go c.readLoop()
+	return c
+}
+
+// newClient creates a client without starting the read loop
+func newClient(conn io.ReadWriteCloser, packager *IsoPackager, codec *framing.Codec, opts ...ClientOption) *Client {
+	c := &Client{
+		conn:     conn,
+		packager: packager,
+		codec:    codec,
+		pending:  make(map[string]chan *Message),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Send writes the request and waits for the matching response
+// until the context is done.
+func (c *Client) Send(ctx context.Context, req *Message) (*Message, error) {
+	if !req.IsRequest() {
+		return nil, fmt.Errorf("%w: %s", ErrNotRequestMti, req.MTI)
+	}
+
+	// GetMessageKey returns a view of the message key buffer, keep a copy
+	key := strings.Clone(req.GetMessageKey())
+	ch := make(chan *Message, 1)
+
+	c.mu.Lock()
+	if _, ok := c.pending[key]; ok {
+		c.mu.Unlock()
+		return nil, ErrDuplicateMessageKey
+	}
+	c.pending[key] = ch
+	c.mu.Unlock()
+
+	if err := c.WriteMessage(req); err != nil {
+		c.removePending(key)
+		return nil, err
+	}
+
+	var err error
+	select {
+	case res := <-ch:
+		return res, nil
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-c.done:
+		err = c.Err()
+	}
+
+	c.removePending(key)
+	// the response may have arrived while giving up
+	select {
+	case res := <-ch:
+		return res, nil
+	default:
+		return nil, err
+	}
+}
+
+// WriteMessage packs and writes msg without waiting for a response
+func (c *Client) WriteMessage(msg *Message) error {
+	b, err := msg.PackISO()
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-c.done:
+		return c.Err()
+	default:
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if err = c.codec.WriteFrame(c.conn, b); err != nil {
+		c.closeWithError(err)
+		return err
+	}
+	return nil
+}
+
+// Close closes the connection, in-flight requests fail with ErrClientClosed
+func (c *Client) Close() error {
+	c.closeWithError(ErrClientClosed)
+	return nil
+}
+
+// Done is closed when the connection is closed
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns the reason the client was closed, nil while it is open
+func (c *Client) Err() error {
+	select {
+	case <-c.done:
+		return c.err
+	default:
+		return nil
+	}
+}
+
+func (c *Client) readLoop() {
+	for {
+		frame, err := c.codec.ReadFrame(c.conn)
+		if err != nil {
+			c.closeWithError(err)
+			return
+		}
+
+		msg := NewMessage(c.packager)
+		if err = msg.Unpack(frame); err != nil {
+			// skip messages we cannot parse, the connection is still usable
+			if c.onError != nil {
+				c.onError(err)
+			}
+			continue
+		}
+
+		c.dispatch(msg)
+	}
+}
+
+// dispatch hands msg to the request waiting for it or to the unmatched handler
+func (c *Client) dispatch(msg *Message) {
+	if !msg.IsRequest() {
+		key := msg.GetMessageKey()
+
+		c.mu.Lock()
+		ch, ok := c.pending[key]
+		delete(c.pending, key)
+		c.mu.Unlock()
+
+		if ok {
+			// buffered, never blocks
+			ch <- msg
+			return
+		}
+	}
+
+	if c.onUnmatched != nil {
+		c.onUnmatched(msg)
+	}
+}
+
+func (c *Client) removePending(key string) {
+	c.mu.Lock()
+	delete(c.pending, key)
+	c.mu.Unlock()
+}
+
+func (c *Client) closeWithError(err error) {
+	c.closeOnce.Do(func() {
+		c.err = err
+		close(c.done)
+		_ = c.conn.Close()
+	})
+}
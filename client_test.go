@@ -0,0 +1,139 @@
+package iso8583
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pentaly7/iso8583/framing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRequest returns a 0200 request of the default packager with the message key fields
+func newTestRequest(stan string) *Message {
+	msg := NewMessage(DefaultPackager())
+	msg.SetMtiString(MTIFinancialRequest)
+	msg.SetString(2, "4111111111111111").
+		SetString(3, "000000").
+		SetString(4, "000000001000").
+		SetString(7, "1018120000").
+		SetString(11, stan).
+		SetString(12, "120000").
+		SetString(13, "1018").
+		SetString(37, "000000000001").
+		SetString(41, "TERM000000000001")
+	return msg
+}
+
+// readTestMessage reads and unpacks a frame from the peer side of a pipe
+func readTestMessage(codec *framing.Codec, conn net.Conn) (*Message, error) {
+	frame, err := codec.ReadFrame(conn)
+	if err != nil {
+		return nil, err
+	}
+	msg := NewMessage(DefaultPackager())
+	return msg, msg.Unpack(frame)
+}
+
+// writeTestMessage packs and writes the message from the peer side of a pipe
+func writeTestMessage(codec *framing.Codec, conn net.Conn, msg *Message) error {
+	b, err := msg.PackISO()
+	if err != nil {
+		return err
+	}
+	return codec.WriteFrame(conn, b)
+}
+
+func TestClientSendMatchesResponse(t *testing.T) {
+	codec := framing.NewCodec(framing.BinaryHeader(2))
+	conn, peer := net.Pipe()
+	defer peer.Close()
+
+	client := NewClient(conn, DefaultPackager(), codec)
+	defer client.Close()
+
+	go func() {
+		req, err := readTestMessage(codec, peer)
+		if err != nil {
+			return
+		}
+		res, err := CreateResponseISO(req, ResponseCodeApproved)
+		if err != nil {
+			return
+		}
+		_ = writeTestMessage(codec, peer, res)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	res, err := client.Send(ctx, newTestRequest("000001"))
+	require.NoError(t, err)
+	assert.Equal(t, "0210", res.MTI.String())
+	assert.Equal(t, "000001", res.GetString(11))
+	assert.Equal(t, ResponseCodeApproved, res.GetString(39))
+}
+
+func TestClientHandlersSetBeforeReading(t *testing.T) {
+	codec := framing.NewCodec(framing.BinaryHeader(2))
+	conn, peer := net.Pipe()
+	defer peer.Close()
+
+	unmatched := make(chan *Message, 1)
+	errs := make(chan error, 1)
+
+	// the peer writes as soon as the connection is up
+	go func() {
+		_ = codec.WriteFrame(peer, []byte("garbage"))
+		_ = writeTestMessage(codec, peer, newTestRequest("000002"))
+	}()
+
+	client := NewClient(conn, DefaultPackager(), codec,
+		WithUnmatchedHandler(func(msg *Message) { unmatched <- msg }),
+		WithErrorHandler(func(err error) { errs <- err }),
+	)
+	defer client.Close()
+
+	select {
+	case err := <-errs:
+		assert.Error(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("error handler not called")
+	}
+	select {
+	case msg := <-unmatched:
+		assert.Equal(t, "000002", msg.GetString(11))
+	case <-time.After(time.Second):
+		t.Fatal("unmatched handler not called")
+	}
+}
+
+func TestClientSendTimeoutAndClose(t *testing.T) {
+	codec := framing.NewCodec(framing.BinaryHeader(2))
+	conn, peer := net.Pipe()
+	defer peer.Close()
+
+	client := NewClient(conn, DefaultPackager(), codec)
+	go func() {
+		// read the requests and never answer
+		for {
+			if _, err := codec.ReadFrame(peer); err != nil {
+				return
+			}
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := client.Send(ctx, newTestRequest("000003"))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = client.Send(context.Background(), newTestRequest("000003").SetMtiString(MTIFinancialResponse))
+	assert.ErrorIs(t, err, ErrNotRequestMti)
+
+	require.NoError(t, client.Close())
+	<-client.Done()
+	_, err = client.Send(context.Background(), newTestRequest("000004"))
+	assert.ErrorIs(t, err, ErrClientClosed)
+}
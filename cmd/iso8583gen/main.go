@@ -0,0 +1,314 @@
+// Command iso8583gen generates typed Go message structs from a packager JSON config.
+//
+// A struct is generated for every rule of the config with a concrete MTI and for every
+// MTI passed with -mti. Each struct wraps an *iso8583.Message and has a getter and
+// setter named after every field (and subfield) of the message, so renaming or removing
+// a field in the config turns its uses into compile errors.
+//
+//	//go:generate go run github.com/pentaly7/iso8583/cmd/iso8583gen -config packager.json -package payments -out messages_gen.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/pentaly7/iso8583"
+)
+
+func main() {
+	var (
+		config = flag.String("config", "", "packager JSON config")
+		pkg    = flag.String("package", "", "package name of the generated file")
+		out    = flag.String("out", "", "output file, stdout when empty")
+		mtis   = flag.String("mti", "", "comma separated MTIs to generate in addition to the rules, e.g. 0200,0210")
+		prefix = flag.String("prefix", "Msg", "type name prefix of messages without a rule name")
+	)
+	flag.Parse()
+
+	if *config == "" || *pkg == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(*config, *pkg, *out, *mtis, *prefix); err != nil {
+		fmt.Fprintln(os.Stderr, "iso8583gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(config, pkg, out, mtis, prefix string) error {
+	f, err := os.Open(config)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	packager, err := iso8583.NewPackager(f)
+	if err != nil {
+		return err
+	}
+
+	var extra []string
+	if mtis != "" {
+		extra = strings.Split(mtis, ",")
+	}
+	file, err := buildFile(packager, pkg, config, prefix, extra)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err = fileTemplate.Execute(&buf, file); err != nil {
+		return err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("formatting generated code: %w", err)
+	}
+
+	if out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(out, src, 0o644)
+}
+
+type genFile struct {
+	Package  string
+	Config   string
+	Messages []genMessage
+}
+
+type genMessage struct {
+	Type   string
+	MTI    string
+	Fields []genField
+}
+
+type genField struct {
+	Bit       int
+	Name      string // Go name of the accessors
+	FieldName string // name in the packager config
+	Subfield  string // subfield name, empty for the field itself
+}
+
+// buildFile collects the messages to generate, one per rule with a concrete MTI
+// followed by the extra MTIs without a rule
+func buildFile(packager *iso8583.IsoPackager, pkg, config, prefix string, extra []string) (*genFile, error) {
+	file := &genFile{Package: pkg, Config: config}
+	seen := make(map[string]bool)
+
+	add := func(mti, name string, bits []int) error {
+		if seen[mti] {
+			return nil
+		}
+		seen[mti] = true
+
+		if !iso8583.MTIType(mti).ToMtiByte().IsValid() || len(mti) != 4 {
+			return fmt.Errorf("invalid mti %q", mti)
+		}
+		typeName := prefix + mti
+		if name != "" {
+			typeName = exportedName(name)
+		}
+		msg, err := buildMessage(packager, typeName, mti, bits)
+		if err != nil {
+			return err
+		}
+		file.Messages = append(file.Messages, msg)
+		return nil
+	}
+
+	for _, rule := range packager.Rules {
+		if strings.ContainsAny(rule.MTI, "xX") {
+			continue
+		}
+		var bits []int
+		if rule.Optional != nil {
+			bits = slices.Concat(rule.Mandatory, rule.Optional)
+			for _, c := range rule.Conditional {
+				bits = append(bits, c.Bit)
+			}
+		}
+		if err := add(rule.MTI, rule.Name, bits); err != nil {
+			return nil, err
+		}
+	}
+	for _, mti := range extra {
+		if err := add(strings.TrimSpace(mti), "", nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(file.Messages) == 0 {
+		return nil, fmt.Errorf("no messages to generate, add rules to the config or use -mti")
+	}
+	return file, nil
+}
+
+// buildMessage collects the accessors of the bits, every configured field when bits is nil
+func buildMessage(packager *iso8583.IsoPackager, typeName, mti string, bits []int) (genMessage, error) {
+	if bits == nil {
+		for bit := 2; bit <= iso8583.MaxBitNumber; bit++ {
+			if packager.PrefixLengths[bit] != 0 {
+				bits = append(bits, bit)
+			}
+		}
+	}
+	slices.Sort(bits)
+	bits = slices.Compact(bits)
+
+	msg := genMessage{Type: typeName, MTI: mti}
+	names := map[string]int{"Message": 0, "Encode": 0}
+	addField := func(f genField) error {
+		accessors := []string{f.Name, "Set" + f.Name}
+		if f.Subfield == "" {
+			accessors = append(accessors, "Has"+f.Name)
+		}
+		for _, name := range accessors {
+			if bit, ok := names[name]; ok {
+				return fmt.Errorf("%s: accessor %s of bit %d clashes with bit %d", typeName, name, f.Bit, bit)
+			}
+			names[name] = f.Bit
+		}
+		msg.Fields = append(msg.Fields, f)
+		return nil
+	}
+
+	for _, bit := range bits {
+		config := packager.IsoPackagerConfig[bit]
+		if packager.PrefixLengths[bit] == 0 {
+			return msg, fmt.Errorf("%s: bit %d is not defined in the packager", typeName, bit)
+		}
+		if bit == 65 && packager.HasTertiaryBitmap {
+			continue
+		}
+
+		name := exportedName(config.Name)
+		if name == "" {
+			name = fmt.Sprintf("Field%d", bit)
+		}
+		if err := addField(genField{Bit: bit, Name: name, FieldName: config.Name}); err != nil {
+			return msg, err
+		}
+
+		if config.Subfields == nil {
+			continue
+		}
+		for _, sf := range config.Subfields.Fields {
+			f := genField{Bit: bit, Name: name + exportedName(sf.Name), FieldName: config.Name, Subfield: sf.Name}
+			if err := addField(f); err != nil {
+				return msg, err
+			}
+		}
+	}
+	return msg, nil
+}
+
+// exportedName turns a field name like "primaryAccountNumber" or "card acceptor-id"
+// into an exported Go identifier
+func exportedName(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if b.Len() == 0 && unicode.IsDigit(r) {
+			b.WriteString("F")
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+var fileTemplate = template.Must(template.New("file").Parse(`// Code generated by iso8583gen from {{.Config}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"fmt"
+
+	"github.com/pentaly7/iso8583"
+)
+{{range .Messages}}{{$type := .Type}}{{$mti := .MTI}}
+// {{$type}} is a typed {{$mti}} message
+type {{$type}} struct {
+	msg *iso8583.Message
+}
+
+// New{{$type}} creates an empty {{$mti}} message
+func New{{$type}}(packager *iso8583.IsoPackager) *{{$type}} {
+	msg := iso8583.NewMessage(packager)
+	msg.SetMtiString("{{$mti}}")
+	return &{{$type}}{msg: msg}
+}
+
+// Decode{{$type}} unpacks a {{$mti}} message
+func Decode{{$type}}(packager *iso8583.IsoPackager, b []byte) (*{{$type}}, error) {
+	msg := iso8583.NewMessage(packager)
+	if err := msg.Unpack(b); err != nil {
+		return nil, err
+	}
+	return As{{$type}}(msg)
+}
+
+// As{{$type}} wraps an unpacked message, it fails when the MTI is not {{$mti}}
+func As{{$type}}(msg *iso8583.Message) (*{{$type}}, error) {
+	if msg.MTI.String() != "{{$mti}}" {
+		return nil, fmt.Errorf("%w: %s is not {{$mti}}", iso8583.ErrUnexpectedMti, msg.MTI)
+	}
+	return &{{$type}}{msg: msg}, nil
+}
+
+// Message returns the underlying message
+func (m *{{$type}}) Message() *iso8583.Message {
+	return m.msg
+}
+
+// Encode validates and packs the message
+func (m *{{$type}}) Encode() ([]byte, error) {
+	if err := m.msg.Validate().Err(); err != nil {
+		return nil, err
+	}
+	return m.msg.PackISO()
+}
+{{range .Fields}}{{if .Subfield}}
+// {{.Name}} returns {{.Subfield}} of DE {{.Bit}}
+func (m *{{$type}}) {{.Name}}() (string, error) {
+	return m.msg.GetSubfield({{.Bit}}, "{{.Subfield}}")
+}
+
+// Set{{.Name}} sets {{.Subfield}} of DE {{.Bit}}
+func (m *{{$type}}) Set{{.Name}}(v string) error {
+	return m.msg.SetSubfield({{.Bit}}, "{{.Subfield}}", v)
+}
+{{else}}
+// {{.Name}} returns DE {{.Bit}}{{if .FieldName}} {{.FieldName}}{{end}}
+func (m *{{$type}}) {{.Name}}() string {
+	return m.msg.GetString({{.Bit}})
+}
+
+// Set{{.Name}} sets DE {{.Bit}}{{if .FieldName}} {{.FieldName}}{{end}}
+func (m *{{$type}}) Set{{.Name}}(v string) *{{$type}} {
+	m.msg.SetString({{.Bit}}, v)
+	return m
+}
+
+// Has{{.Name}} reports whether DE {{.Bit}} is set
+func (m *{{$type}}) Has{{.Name}}() bool {
+	return m.msg.HasBit({{.Bit}})
+}
+{{end}}{{end}}{{end}}`))
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `{
+  "rules": [{"mti": "0200", "name": "purchase request", "mandatory": [2, 3], "optional": [4]}],
+  "packagerConfig": {
+    "2": {"name": "primaryAccountNumber", "type": "n", "length": {"type": "LLVAR", "max": 19}},
+    "3": {"name": "processingCode", "type": "n", "length": {"type": "FIXED", "max": 6}, "subfields": {"fields": [
+      {"name": "transactionType", "type": "n", "length": 2},
+      {"name": "fromAccount", "type": "n", "length": 2},
+      {"name": "toAccount", "type": "n", "length": 2}
+    ]}},
+    "4": {"name": "amount", "type": "n", "length": {"type": "FIXED", "max": 12}},
+    "39": {"type": "an", "length": {"type": "FIXED", "max": 2}}
+  }
+}`
+
+// writeTestConfig writes the config into a temporary directory and returns its path
+func writeTestConfig(t *testing.T, config string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "packager.json")
+	require.NoError(t, os.WriteFile(path, []byte(config), 0o644))
+	return path
+}
+
+func TestRun(t *testing.T) {
+	config := writeTestConfig(t, testConfig)
+	out := filepath.Join(t.TempDir(), "messages_gen.go")
+	require.NoError(t, run(config, "payments", out, "0210", "Msg"))
+
+	b, err := os.ReadFile(out)
+	require.NoError(t, err)
+	src := string(b)
+
+	assert.Contains(t, src, "package payments")
+	assert.Contains(t, src, "type PurchaseRequest struct")
+	assert.Contains(t, src, "func (m *PurchaseRequest) SetPrimaryAccountNumber(v string) *PurchaseRequest")
+	assert.Contains(t, src, "func (m *PurchaseRequest) ProcessingCodeFromAccount() (string, error)")
+	assert.NotContains(t, src, "func (m *PurchaseRequest) Field39()", "the rule lists the fields")
+
+	assert.Contains(t, src, "type Msg0210 struct")
+	assert.Contains(t, src, "func (m *Msg0210) Field39() string", "a field without a name is named after its bit")
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		mtis   string
+	}{
+		{"no messages", `{"packagerConfig": {"2": {"type": "n", "length": {"type": "LLVAR", "max": 19}}}}`, ""},
+		{"invalid mti", `{"packagerConfig": {}}`, "02"},
+		{"undefined bit", `{"rules": [{"mti": "0200", "mandatory": [2], "optional": []}], "packagerConfig": {}}`, ""},
+		{"accessor clash", `{"packagerConfig": {
+			"2": {"name": "card number", "type": "n", "length": {"type": "LLVAR", "max": 19}},
+			"34": {"name": "card-number", "type": "n", "length": {"type": "LLVAR", "max": 28}}
+		}}`, "0200"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := writeTestConfig(t, tt.config)
+			assert.Error(t, run(config, "payments", filepath.Join(t.TempDir(), "out.go"), tt.mtis, "Msg"))
+		})
+	}
+}
+
+func TestExportedName(t *testing.T) {
+	tests := map[string]string{
+		"primaryAccountNumber": "PrimaryAccountNumber",
+		"card acceptor-id":     "CardAcceptorId",
+		"3dsData":              "F3dsData",
+		"":                     "",
+		"--":                   "",
+	}
+	for name, want := range tests {
+		assert.Equal(t, want, exportedName(name), name)
+	}
+}
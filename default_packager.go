@@ -2,13 +2,16 @@ package iso8583
 
 func DefaultPackager() *IsoPackager {
 	packager := &IsoPackager{
-		HasHeader:    false,
-		HeaderLength: 0,
-		MessageKey:   []int{2, 7, 11, 12, 13, 41, 37},
-		IsoPackagerConfig: [129]BitConfig{
+		HasHeader:         false,
+		HeaderLength:      0,
+		MTIEncoding:       EncodingASCII,
+		BitmapEncoding:    EncodingASCII,
+		HasTertiaryBitmap: false,
+		MessageKey:        []int{2, 7, 11, 12, 13, 41, 37},
+		IsoPackagerConfig: [MaxBitNumber + 1]BitConfig{
 			1:   NewBitConfigFixed(true, BitTypeB, 16),
 			2:   NewBitConfigLLVar(true, BitTypeN, 19),
-			3:   NewBitConfigFixed(false, BitTypeANS, 6),
+			3:   NewBitConfigFixed(false, BitTypeANS, 6).WithSubfields(processingCodeSubfields()),
 			4:   NewBitConfigFixed(false, BitTypeANS, 12),
 			5:   NewBitConfigFixed(false, BitTypeANS, 12),
 			6:   NewBitConfigFixed(false, BitTypeANS, 12),
@@ -27,7 +30,7 @@ func DefaultPackager() *IsoPackager {
 			19:  NewBitConfigFixed(false, BitTypeANS, 4),
 			20:  NewBitConfigFixed(false, BitTypeANS, 4),
 			21:  NewBitConfigFixed(false, BitTypeANS, 3),
-			22:  NewBitConfigFixed(false, BitTypeANS, 3),
+			22:  NewBitConfigFixed(false, BitTypeANS, 3).WithSubfields(posEntryModeSubfields()),
 			23:  NewBitConfigFixed(false, BitTypeANS, 3),
 			24:  NewBitConfigFixed(false, BitTypeANS, 3),
 			25:  NewBitConfigFixed(false, BitTypeANS, 2),
@@ -95,7 +98,7 @@ func DefaultPackager() *IsoPackager {
 			87:  NewBitConfigFixed(false, BitTypeANS, 16),
 			88:  NewBitConfigFixed(false, BitTypeANS, 16),
 			89:  NewBitConfigFixed(false, BitTypeANS, 16),
-			90:  NewBitConfigFixed(false, BitTypeANS, 42),
+			90:  NewBitConfigFixed(false, BitTypeANS, 42).WithSubfields(originalDataElementsSubfields()),
 			91:  NewBitConfigFixed(false, BitTypeANS, 1),
 			92:  NewBitConfigFixed(false, BitTypeANS, 2),
 			93:  NewBitConfigFixed(false, BitTypeANS, 5),
@@ -137,20 +140,56 @@ func DefaultPackager() *IsoPackager {
 		},
 	}
 
-	packager.MandatoryBit = packager.GetMandatoryBitsFromConfig()
+	packager.setFieldNames(fieldNames1987[:])
+	packager.precomputeConfig()
 
-	// Convert map to array for direct access
-	for k, v := range packager.IsoPackagerConfig {
-		if k >= 0 && k <= 128 {
-			packager.IsoPackagerConfig[k] = v
+	return packager
+}
+
+// precomputeConfig pre-computes the values of the packagers defined in code
+func (p *IsoPackager) precomputeConfig() {
+	p.MandatoryBit = p.GetMandatoryBitsFromConfig()
 
-			// Pre-compute values for faster access
-			packager.PrefixLengths[k] = v.Length.Type.GetPrefixLen()
-			packager.MaxLengths[k] = v.Length.Max
+	// Pre-compute values for faster access
+	for k, v := range p.IsoPackagerConfig {
+		if err := p.setBitConfig(k, v); err != nil {
+			panic(err)
 		}
 	}
+}
 
-	return packager
+// WithSubfields returns the config with the subfields
+func (c BitConfig) WithSubfields(subfields *Subfields) BitConfig {
+	c.Subfields = subfields
+	return c
+}
+
+// processingCodeSubfields is DE 3
+func processingCodeSubfields() *Subfields {
+	return NewSubfieldsFixed(
+		SubfieldConfig{Name: "transactionType", Type: BitTypeN, Length: 2},
+		SubfieldConfig{Name: "fromAccount", Type: BitTypeN, Length: 2},
+		SubfieldConfig{Name: "toAccount", Type: BitTypeN, Length: 2},
+	)
+}
+
+// posEntryModeSubfields is DE 22 of ISO 8583:1987
+func posEntryModeSubfields() *Subfields {
+	return NewSubfieldsFixed(
+		SubfieldConfig{Name: "panEntryMode", Type: BitTypeN, Length: 2},
+		SubfieldConfig{Name: "pinEntryCapability", Type: BitTypeN, Length: 1},
+	)
+}
+
+// originalDataElementsSubfields is DE 90 of ISO 8583:1987
+func originalDataElementsSubfields() *Subfields {
+	return NewSubfieldsFixed(
+		SubfieldConfig{Name: "originalMti", Type: BitTypeN, Length: 4},
+		SubfieldConfig{Name: "originalStan", Type: BitTypeN, Length: 6},
+		SubfieldConfig{Name: "originalTransmissionDateTime", Type: BitTypeN, Length: 10},
+		SubfieldConfig{Name: "originalAcquiringInstitutionId", Type: BitTypeN, Length: originalInstitutionLength},
+		SubfieldConfig{Name: "originalForwardingInstitutionId", Type: BitTypeN, Length: originalInstitutionLength},
+	)
 }
 
 func NewBitConfigFixed(isMandatory bool, bitType BitType, length int) BitConfig {
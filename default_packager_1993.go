@@ -0,0 +1,168 @@
+package iso8583
+
+// DefaultPackager1993 returns the ISO 8583:1993 field definitions with ASCII encoding.
+// DE 12 is the 12 digit local date and time (YYMMDDhhmmss), DE 24 the function code,
+// DE 39 the 3 digit action code and DE 56 the original data elements.
+func DefaultPackager1993() *IsoPackager {
+	packager := &IsoPackager{
+		HasHeader:         false,
+		HeaderLength:      0,
+		MTIEncoding:       EncodingASCII,
+		BitmapEncoding:    EncodingASCII,
+		HasTertiaryBitmap: false,
+		MessageKey:        []int{2, 7, 11, 12, 41, 37},
+		IsoPackagerConfig: [MaxBitNumber + 1]BitConfig{
+			1:   NewBitConfigFixed(true, BitTypeB, 16),
+			2:   NewBitConfigLLVar(true, BitTypeN, 19),
+			3:   NewBitConfigFixed(false, BitTypeN, 6).WithSubfields(processingCodeSubfields()),
+			4:   NewBitConfigFixed(false, BitTypeN, 12),
+			5:   NewBitConfigFixed(false, BitTypeN, 12),
+			6:   NewBitConfigFixed(false, BitTypeN, 12),
+			7:   NewBitConfigFixed(true, BitTypeN, 10),
+			8:   NewBitConfigFixed(false, BitTypeN, 8),
+			9:   NewBitConfigFixed(false, BitTypeN, 8),
+			10:  NewBitConfigFixed(false, BitTypeN, 8),
+			11:  NewBitConfigFixed(true, BitTypeN, 6),
+			12:  NewBitConfigFixed(true, BitTypeN, 12),
+			13:  NewBitConfigFixed(false, BitTypeN, 4),
+			14:  NewBitConfigFixed(false, BitTypeN, 4),
+			15:  NewBitConfigFixed(false, BitTypeN, 6),
+			16:  NewBitConfigFixed(false, BitTypeN, 4),
+			17:  NewBitConfigFixed(false, BitTypeN, 4),
+			18:  NewBitConfigFixed(false, BitTypeN, 4),
+			19:  NewBitConfigFixed(false, BitTypeN, 3),
+			20:  NewBitConfigFixed(false, BitTypeN, 3),
+			21:  NewBitConfigFixed(false, BitTypeN, 3),
+			22:  NewBitConfigFixed(false, BitTypeAN, 12).WithSubfields(posDataCodeSubfields()),
+			23:  NewBitConfigFixed(false, BitTypeN, 3),
+			24:  NewBitConfigFixed(false, BitTypeN, 3),
+			25:  NewBitConfigFixed(false, BitTypeN, 4),
+			26:  NewBitConfigFixed(false, BitTypeN, 4),
+			27:  NewBitConfigFixed(false, BitTypeN, 1),
+			28:  NewBitConfigFixed(false, BitTypeN, 6),
+			29:  NewBitConfigFixed(false, BitTypeN, 3),
+			30:  NewBitConfigFixed(false, BitTypeN, 24),
+			31:  NewBitConfigLLVar(false, BitTypeANS, 99),
+			32:  NewBitConfigLLVar(false, BitTypeN, 11),
+			33:  NewBitConfigLLVar(false, BitTypeN, 11),
+			34:  NewBitConfigLLVar(false, BitTypeANS, 28),
+			35:  NewBitConfigLLVar(false, BitTypeZ, 37),
+			36:  NewBitConfigLLLVar(false, BitTypeZ, 104),
+			37:  NewBitConfigFixed(true, BitTypeANS, 12),
+			38:  NewBitConfigFixed(false, BitTypeANS, 6),
+			39:  NewBitConfigFixed(false, BitTypeN, 3),
+			40:  NewBitConfigFixed(false, BitTypeN, 3),
+			41:  NewBitConfigFixed(false, BitTypeANS, 8),
+			42:  NewBitConfigFixed(false, BitTypeANS, 15),
+			43:  NewBitConfigLLVar(false, BitTypeANS, 99),
+			44:  NewBitConfigLLVar(false, BitTypeANS, 99),
+			45:  NewBitConfigLLVar(false, BitTypeANS, 76),
+			46:  NewBitConfigLLLVar(false, BitTypeANS, 204),
+			47:  NewBitConfigLLLVar(false, BitTypeANS, 999),
+			48:  NewBitConfigLLLVar(false, BitTypeANS, 999),
+			49:  NewBitConfigFixed(false, BitTypeAN, 3),
+			50:  NewBitConfigFixed(false, BitTypeAN, 3),
+			51:  NewBitConfigFixed(false, BitTypeAN, 3),
+			52:  NewBitConfigFixed(false, BitTypeB, 16),
+			53:  NewBitConfigLLVar(false, BitTypeB, 96),
+			54:  NewBitConfigLLLVar(false, BitTypeANS, 120),
+			55:  NewBitConfigLLLVar(false, BitTypeB, 510),
+			56:  NewBitConfigLLVar(false, BitTypeN, 35),
+			57:  NewBitConfigFixed(false, BitTypeN, 3),
+			58:  NewBitConfigLLVar(false, BitTypeN, 11),
+			59:  NewBitConfigLLLVar(false, BitTypeANS, 999),
+			60:  NewBitConfigLLLVar(false, BitTypeANS, 999),
+			61:  NewBitConfigLLLVar(false, BitTypeANS, 999),
+			62:  NewBitConfigLLLVar(false, BitTypeANS, 999),
+			63:  NewBitConfigLLLVar(false, BitTypeANS, 999),
+			64:  NewBitConfigFixed(false, BitTypeB, 16),
+			65:  NewBitConfigFixed(false, BitTypeB, 2),
+			66:  NewBitConfigLLLVar(false, BitTypeANS, 204),
+			67:  NewBitConfigFixed(false, BitTypeN, 2),
+			68:  NewBitConfigFixed(false, BitTypeN, 3),
+			69:  NewBitConfigFixed(false, BitTypeN, 3),
+			70:  NewBitConfigFixed(false, BitTypeN, 3),
+			71:  NewBitConfigFixed(false, BitTypeN, 8),
+			72:  NewBitConfigLLLVar(false, BitTypeANS, 999),
+			73:  NewBitConfigFixed(false, BitTypeN, 6),
+			74:  NewBitConfigFixed(false, BitTypeN, 10),
+			75:  NewBitConfigFixed(false, BitTypeN, 10),
+			76:  NewBitConfigFixed(false, BitTypeN, 10),
+			77:  NewBitConfigFixed(false, BitTypeN, 10),
+			78:  NewBitConfigFixed(false, BitTypeN, 10),
+			79:  NewBitConfigFixed(false, BitTypeN, 10),
+			80:  NewBitConfigFixed(false, BitTypeN, 10),
+			81:  NewBitConfigFixed(false, BitTypeN, 10),
+			82:  NewBitConfigFixed(false, BitTypeN, 10),
+			83:  NewBitConfigFixed(false, BitTypeN, 10),
+			84:  NewBitConfigFixed(false, BitTypeN, 10),
+			85:  NewBitConfigFixed(false, BitTypeN, 10),
+			86:  NewBitConfigFixed(false, BitTypeN, 16),
+			87:  NewBitConfigFixed(false, BitTypeN, 16),
+			88:  NewBitConfigFixed(false, BitTypeN, 16),
+			89:  NewBitConfigFixed(false, BitTypeN, 16),
+			90:  NewBitConfigFixed(false, BitTypeN, 10),
+			91:  NewBitConfigFixed(false, BitTypeN, 3),
+			92:  NewBitConfigFixed(false, BitTypeN, 3),
+			93:  NewBitConfigLLVar(false, BitTypeN, 11),
+			94:  NewBitConfigLLVar(false, BitTypeN, 11),
+			95:  NewBitConfigLLVar(false, BitTypeANS, 99),
+			96:  NewBitConfigLLLVar(false, BitTypeB, 999),
+			97:  NewBitConfigFixed(false, BitTypeANS, 17),
+			98:  NewBitConfigFixed(false, BitTypeANS, 25),
+			99:  NewBitConfigLLVar(false, BitTypeAN, 11),
+			100: NewBitConfigLLVar(false, BitTypeN, 11),
+			101: NewBitConfigLLVar(false, BitTypeANS, 17),
+			102: NewBitConfigLLVar(false, BitTypeANS, 28),
+			103: NewBitConfigLLVar(false, BitTypeANS, 28),
+			104: NewBitConfigLLLVar(false, BitTypeANS, 100),
+			105: NewBitConfigFixed(false, BitTypeN, 16),
+			106: NewBitConfigFixed(false, BitTypeN, 16),
+			107: NewBitConfigFixed(false, BitTypeN, 10),
+			108: NewBitConfigFixed(false, BitTypeN, 10),
+			109: NewBitConfigLLVar(false, BitTypeANS, 84),
+			110: NewBitConfigLLVar(false, BitTypeANS, 84),
+			111: NewBitConfigLLLVar(false, BitTypeANS, 999),
+			112: NewBitConfigLLLVar(false, BitTypeANS, 999),
+			113: NewBitConfigLLLVar(false, BitTypeANS, 999),
+			114: NewBitConfigLLLVar(false, BitTypeANS, 999),
+			115: NewBitConfigLLLVar(false, BitTypeANS, 999),
+			116: NewBitConfigLLLVar(false, BitTypeANS, 999),
+			117: NewBitConfigLLLVar(false, BitTypeANS, 999),
+			118: NewBitConfigLLLVar(false, BitTypeANS, 999),
+			119: NewBitConfigLLLVar(false, BitTypeANS, 999),
+			120: NewBitConfigLLLVar(false, BitTypeANS, 999),
+			121: NewBitConfigLLLVar(false, BitTypeANS, 999),
+			122: NewBitConfigLLLVar(false, BitTypeANS, 999),
+			123: NewBitConfigLLLVar(false, BitTypeANS, 999),
+			124: NewBitConfigLLLVar(false, BitTypeANS, 999),
+			125: NewBitConfigLLLVar(false, BitTypeANS, 999),
+			126: NewBitConfigLLLVar(false, BitTypeANS, 999),
+			127: NewBitConfigLLLVar(false, BitTypeANS, 999),
+			128: NewBitConfigFixed(false, BitTypeB, 16),
+		},
+	}
+
+	for bit, name := range fieldNames1993 {
+		packager.IsoPackagerConfig[bit].Name = name
+	}
+	packager.setFieldNames(fieldNames1987[:])
+	packager.precomputeConfig()
+
+	return packager
+}
+
+// posDataCodeSubfields is the 12 position DE 22 of ISO 8583:1993
+func posDataCodeSubfields() *Subfields {
+	names := []string{
+		"cardDataInputCapability", "cardholderAuthenticationCapability", "cardCaptureCapability",
+		"operatingEnvironment", "cardholderPresent", "cardPresent", "cardDataInputMode",
+		"cardholderAuthenticationMethod", "cardholderAuthenticationEntity", "cardDataOutputCapability",
+		"terminalOutputCapability", "pinCaptureCapability",
+	}
+	fields := make([]SubfieldConfig, len(names))
+	for i, name := range names {
+		fields[i] = SubfieldConfig{Name: name, Type: BitTypeAN, Length: 1}
+	}
+	return NewSubfieldsFixed(fields...)
+}
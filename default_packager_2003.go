@@ -0,0 +1,11 @@
+package iso8583
+
+// DefaultPackager2003 is an alias of DefaultPackager1993, it returns the 1993 field definitions.
+//
+// The package only tells 2003 apart by the version digit of the MTI (2xxx):
+// MTI versioning, network management (x804, DE 24) and reversals (x420, DE 56) follow the 1993
+// rules. The 2003 field changes differ between networks and are not modelled, adjust the fields
+// of a 2003 network with SetBitConfig or load a JSON config.
+func DefaultPackager2003() *IsoPackager {
+	return DefaultPackager1993()
+}
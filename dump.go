@@ -0,0 +1,184 @@
+package iso8583
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// messageJSON is the JSON form of a message, fields are keyed by bit number
+type messageJSON struct {
+	MTI       string            `json:"mti"`
+	Header    string            `json:"header,omitempty"`
+	HeaderHex string            `json:"headerHex,omitempty"` // a header that is not printable
+	Fields    map[string]string `json:"fields"`
+}
+
+// sortedBits returns the set bits in ascending order
+func (m *Message) sortedBits() []int {
+	bits := slices.Clone(m.activeBits[:m.activeCount])
+	slices.Sort(bits)
+	return bits
+}
+
+// MarshalJSON implements json.Marshaler, the fields are in bit order
+// and redacted by their Sensitivity. A header that is not printable
+// is written in hex as headerHex.
+//
+//	{"mti":"0200","fields":{"2":"411111******1111","3":"000000","4":"000000001000"}}
+func (m *Message) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(`{"mti":`)
+	writeJSONString(&buf, m.MTI.String())
+	if len(m.header) > 0 {
+		if isPrintable(m.header) {
+			buf.WriteString(`,"header":`)
+			writeJSONString(&buf, string(m.header))
+		} else {
+			buf.WriteString(`,"headerHex":`)
+			writeJSONString(&buf, hex.EncodeToString(m.header))
+		}
+	}
+
+	buf.WriteString(`,"fields":{`)
+	first := true
+	for _, bit := range m.sortedBits() {
+		value, ok, err := m.redactedValue(bit)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		buf.WriteByte('"')
+		buf.WriteString(strconv.Itoa(bit))
+		buf.WriteString(`":`)
+		writeJSONString(&buf, value)
+	}
+	buf.WriteString("}}")
+	return buf.Bytes(), nil
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	b, _ := json.Marshal(s) // marshalling a string never fails
+	buf.Write(b)
+}
+
+// isPrintable reports whether b holds only printable ASCII characters
+func isPrintable(b []byte) bool {
+	for _, c := range b {
+		if c < ' ' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// UnmarshalJSON implements json.Unmarshaler, the fields of the message are replaced.
+// Masked values are read as they are, so a masked message does not round trip.
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var v messageJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if len(v.MTI) != len(MTITypeByte{}) {
+		return fmt.Errorf("%w: mti %q", ErrInvalidValue, v.MTI)
+	}
+
+	m.ClearEntries()
+	m.SetMtiString(MTIType(v.MTI))
+	m.header = nil
+	switch {
+	case v.HeaderHex != "":
+		header, err := hex.DecodeString(v.HeaderHex)
+		if err != nil {
+			return fmt.Errorf("%w: header %q", ErrInvalidValue, v.HeaderHex)
+		}
+		m.header = header
+	case v.Header != "":
+		m.header = []byte(v.Header)
+	}
+	for key, value := range v.Fields {
+		bit, err := strconv.Atoi(key)
+		if err != nil || bit < 2 || bit > MaxBitNumber {
+			return fmt.Errorf("%w: %q", ErrInvalidBitNumber, key)
+		}
+		m.SetString(bit, value)
+	}
+	return nil
+}
+
+// Dump writes the message in the jPOS style, one field per line in bit order
+// with its name, length type, length and value redacted by its Sensitivity,
+// ErrHashKeyNotSet when a field is hashed and the packager has no hash key
+//
+//	<isomsg mti="0200">
+//	  <field id="2" name="primaryAccountNumber" type="LLVAR" length="16" value="411111******1111"/>
+//	</isomsg>
+func (m *Message) Dump(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString(`<isomsg mti="`)
+	b.WriteString(m.MTI.String())
+	b.WriteString(`">` + "\n")
+	if len(m.header) > 0 {
+		if isPrintable(m.header) {
+			b.WriteString(`  <header value="`)
+			writeXMLEscaped(&b, string(m.header))
+		} else {
+			b.WriteString(`  <header hex="`)
+			b.WriteString(hex.EncodeToString(m.header))
+		}
+		b.WriteString(`"/>` + "\n")
+	}
+
+	for _, bit := range m.sortedBits() {
+		value, ok, err := m.redactedValue(bit)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&b, `  <field id="%d"`, bit)
+		if m.packager != nil {
+			config := &m.packager.IsoPackagerConfig[bit]
+			if config.Name != "" {
+				b.WriteString(` name="`)
+				writeXMLEscaped(&b, config.Name)
+				b.WriteByte('"')
+			}
+			if config.Length.Type != "" {
+				fmt.Fprintf(&b, ` type="%s"`, config.Length.Type)
+			}
+		}
+		fmt.Fprintf(&b, ` length="%d" value="`, len(m.isoMessageMap[bit]))
+		writeXMLEscaped(&b, value)
+		b.WriteString(`"/>` + "\n")
+	}
+	b.WriteString("</isomsg>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// String returns the Dump of the message, sensitive fields are redacted.
+// It is empty when the Dump fails, e.g. a hashed field without a hash key.
+func (m *Message) String() string {
+	var b strings.Builder
+	_ = m.Dump(&b)
+	return b.String()
+}
+
+func writeXMLEscaped(b *strings.Builder, s string) {
+	_ = xml.EscapeText(b, []byte(s)) // strings.Builder never fails
+}
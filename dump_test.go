@@ -0,0 +1,99 @@
+package iso8583
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDumpTestMessage(t *testing.T) *Message {
+	t.Helper()
+	packager := newTestPackager(t, "",
+		`"2": {"name": "primaryAccountNumber", "type": "n", "length": {"type": "LLVAR", "max": 19}}`,
+		`"4": {"name": "amount", "type": "n", "length": {"type": "FIXED", "max": 12}}`,
+		`"43": {"name": "cardAcceptorName", "type": "ans", "length": {"type": "LLVAR", "max": 40}}`,
+		`"52": {"name": "pinBlock", "type": "b", "length": {"type": "FIXED", "max": 16}}`,
+		`"62": {"type": "ans", "length": {"type": "LLLVAR", "max": 999}, "sensitivity": "drop"}`,
+	)
+	msg := NewMessage(packager)
+	msg.SetMtiString("0200")
+	msg.SetString(43, `SHOP "A" & <B>`)
+	msg.SetString(4, "000000001000")
+	msg.SetString(2, "4111111111111111")
+	msg.SetString(52, "0123456789ABCDEF")
+	msg.SetString(62, "private")
+	return msg
+}
+
+func TestMessageMarshalJSON(t *testing.T) {
+	msg := newDumpTestMessage(t)
+
+	b, err := json.Marshal(msg)
+	require.NoError(t, err)
+	assert.Equal(t, `{"mti":"0200","fields":{"2":"411111******1111","4":"000000001000",`+
+		`"43":"SHOP \"A\" \u0026 \u003cB\u003e","52":"****************"}}`, string(b))
+
+	out := NewMessage(msg.packager)
+	require.NoError(t, json.Unmarshal(b, out))
+	assert.Equal(t, msg.MTI, out.MTI)
+	assert.Equal(t, "000000001000", out.GetString(4))
+	assert.Equal(t, `SHOP "A" & <B>`, out.GetString(43))
+	assert.Equal(t, "411111******1111", out.GetString(2), "masked values do not round trip")
+	assert.False(t, out.HasBit(62))
+}
+
+func TestMessageUnmarshalJSONMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  error
+	}{
+		{"mti", `{"mti":"02","fields":{}}`, ErrInvalidValue},
+		{"bit 1", `{"mti":"0200","fields":{"1":"x"}}`, ErrInvalidBitNumber},
+		{"bit 193", `{"mti":"0200","fields":{"193":"x"}}`, ErrInvalidBitNumber},
+		{"bit name", `{"mti":"0200","fields":{"pan":"x"}}`, ErrInvalidBitNumber},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := json.Unmarshal([]byte(tt.data), NewMessage(DefaultPackager()))
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+	assert.Error(t, json.Unmarshal([]byte(`{"mti":0200}`), NewMessage(DefaultPackager())))
+}
+
+func TestMessageDump(t *testing.T) {
+	msg := newDumpTestMessage(t)
+	assert.Equal(t, `<isomsg mti="0200">
+  <field id="2" name="primaryAccountNumber" type="LLVAR" length="16" value="411111******1111"/>
+  <field id="4" name="amount" type="FIXED" length="12" value="000000001000"/>
+  <field id="43" name="cardAcceptorName" type="LLVAR" length="14" value="SHOP &#34;A&#34; &amp; &lt;B&gt;"/>
+  <field id="52" name="pinBlock" type="FIXED" length="16" value="****************"/>
+</isomsg>
+`, msg.String())
+}
+
+func TestMessageHeader(t *testing.T) {
+	msg := newDumpTestMessage(t)
+	msg.header = []byte("ISO0060000")
+
+	b, err := json.Marshal(msg)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"header":"ISO0060000"`)
+	assert.Contains(t, msg.String(), `<header value="ISO0060000"/>`)
+
+	msg.header = []byte{0x60, 0x00, 0x03, 0x00, 0x00}
+	b, err = json.Marshal(msg)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"headerHex":"6000030000"`)
+	assert.Contains(t, msg.String(), `<header hex="6000030000"/>`)
+
+	out := NewMessage(msg.packager)
+	require.NoError(t, json.Unmarshal(b, out))
+	assert.Equal(t, msg.header, out.header, "a binary header round trips")
+
+	err = json.Unmarshal([]byte(`{"mti":"0200","headerHex":"6z","fields":{}}`), out)
+	assert.ErrorIs(t, err, ErrInvalidValue)
+}
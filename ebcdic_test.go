@@ -0,0 +1,89 @@
+package iso8583
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEBCDICTablesRoundTrip(t *testing.T) {
+	all := make([]byte, 256)
+	for i := range all {
+		all[i] = byte(i)
+	}
+
+	for _, encoding := range []Encoding{EncodingEBCDIC, EncodingEBCDIC1047} {
+		encoded := make([]byte, len(all))
+		decoded := make([]byte, len(all))
+		asciiToEBCDIC(encoded, all, encoding)
+		ebcdicToASCII(decoded, encoded, encoding)
+		assert.Equal(t, all, decoded, "%s", encoding)
+	}
+
+	// '[' is one of the code page 1047 swaps
+	dst := make([]byte, 1)
+	asciiToEBCDIC(dst, []byte("["), EncodingEBCDIC)
+	assert.Equal(t, byte(0xBA), dst[0])
+	asciiToEBCDIC(dst, []byte("["), EncodingEBCDIC1047)
+	assert.Equal(t, byte(0xAD), dst[0])
+}
+
+func TestEBCDICMessageRoundTrip(t *testing.T) {
+	packager := newTestPackager(t, `"mtiEncoding": "ebcdic", "bitmapEncoding": "ebcdic",`,
+		`"2": {"type": "n", "encoding": "ebcdic", "length": {"type": "LLVAR", "max": 19, "encoding": "ebcdic"}}`,
+		`"3": {"type": "n", "encoding": "ebcdic", "length": {"type": "FIXED", "max": 6}}`,
+		`"43": {"type": "ans", "encoding": "ebcdic1047", "length": {"type": "LLVAR", "max": 40, "encoding": "ebcdic"}}`,
+	)
+
+	msg := NewMessage(packager)
+	msg.SetMtiString("0200")
+	msg.SetString(2, "4111111111111111")
+	msg.SetString(3, "000000")
+	msg.SetString(43, "SHOP [1]")
+
+	b, err := msg.PackISO()
+	require.NoError(t, err)
+
+	want := ebcdicDigits("0200" + "6000000000200000" + "16" + "4111111111111111" + "000000" + "08")
+	want = append(want, 0xE2, 0xC8, 0xD6, 0xD7, 0x40, 0xAD, 0xF1, 0xBD) // SHOP [1] in code page 1047
+	assert.Equal(t, want, b)
+
+	out := NewMessage(packager)
+	require.NoError(t, out.Unpack(b))
+	assert.Equal(t, MTITypeByte{'0', '2', '0', '0'}, out.MTI)
+	assert.Equal(t, "4111111111111111", out.GetString(2))
+	assert.Equal(t, "000000", out.GetString(3))
+	assert.Equal(t, "SHOP [1]", out.GetString(43))
+}
+
+// ebcdicDigits returns the EBCDIC bytes of the digits, 0xF0 - 0xF9
+func ebcdicDigits(digits string) []byte {
+	b := []byte(digits)
+	for i := range b {
+		b[i] |= 0xF0
+	}
+	return b
+}
+
+func TestEBCDICUnpackMalformed(t *testing.T) {
+	packager := newTestPackager(t, `"mtiEncoding": "ebcdic", "bitmapEncoding": "ebcdic",`,
+		`"2": {"type": "n", "encoding": "ebcdic", "length": {"type": "LLVAR", "max": 19, "encoding": "ebcdic"}}`,
+	)
+	mti := ebcdicDigits("0200")
+	bitmap := ebcdicDigits("4000000000000000")
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"ascii bitmap", append(mti[:4:4], "4000000000000000"...)},
+		{"ascii length", append(append(mti[:4:4], bitmap...), "16"...)},
+		{"ascii mti", append([]byte("0200"), bitmap...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, NewMessage(packager).Unpack(tt.data))
+		})
+	}
+}
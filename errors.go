@@ -19,10 +19,14 @@ var (
 	ErrInvalidBitNumber            = errors.New("invalid bit number")
 	ErrNoMtiToPack                 = errors.New("no mti to pack")
 	ErrNotDefaultMti               = errors.New("not default mti to pack")
+	ErrNotRequestMti               = errors.New("mti is not a request")
+	ErrUnexpectedMti               = errors.New("unexpected mti")
 	ErrInvalidPackager             = errors.New("invalid packager value")
 )
 
 var (
-	ErrInvalidBitType = errors.New("invalid bit type")
-	ErrInvalidBitMap  = errors.New("invalid bitmap")
+	ErrInvalidBitType  = errors.New("invalid bit type")
+	ErrInvalidBitMap   = errors.New("invalid bitmap")
+	ErrInvalidEncoding = errors.New("invalid encoding")
+	ErrInvalidPadding  = errors.New("invalid padding")
 )
@@ -0,0 +1,154 @@
+package iso8583
+
+// fieldNames1987 are the ISO 8583:1987 data element names
+var fieldNames1987 = [maxSecondaryBitNumber + 1]string{
+	1:   "secondaryBitmap",
+	2:   "primaryAccountNumber",
+	3:   "processingCode",
+	4:   "amountTransaction",
+	5:   "amountSettlement",
+	6:   "amountCardholderBilling",
+	7:   "transmissionDateTime",
+	8:   "amountCardholderBillingFee",
+	9:   "conversionRateSettlement",
+	10:  "conversionRateCardholderBilling",
+	11:  "systemTraceAuditNumber",
+	12:  "localTransactionTime",
+	13:  "localTransactionDate",
+	14:  "expirationDate",
+	15:  "settlementDate",
+	16:  "conversionDate",
+	17:  "captureDate",
+	18:  "merchantType",
+	19:  "acquiringInstitutionCountryCode",
+	20:  "panExtendedCountryCode",
+	21:  "forwardingInstitutionCountryCode",
+	22:  "posEntryMode",
+	23:  "cardSequenceNumber",
+	24:  "networkInternationalId",
+	25:  "posConditionCode",
+	26:  "posCaptureCode",
+	27:  "authorizationIdResponseLength",
+	28:  "amountTransactionFee",
+	29:  "amountSettlementFee",
+	30:  "amountTransactionProcessingFee",
+	31:  "amountSettlementProcessingFee",
+	32:  "acquiringInstitutionId",
+	33:  "forwardingInstitutionId",
+	34:  "primaryAccountNumberExtended",
+	35:  "track2Data",
+	36:  "track3Data",
+	37:  "retrievalReferenceNumber",
+	38:  "authorizationIdResponse",
+	39:  "responseCode",
+	40:  "serviceRestrictionCode",
+	41:  "cardAcceptorTerminalId",
+	42:  "cardAcceptorIdCode",
+	43:  "cardAcceptorNameLocation",
+	44:  "additionalResponseData",
+	45:  "track1Data",
+	46:  "additionalDataIso",
+	47:  "additionalDataNational",
+	48:  "additionalDataPrivate",
+	49:  "currencyCodeTransaction",
+	50:  "currencyCodeSettlement",
+	51:  "currencyCodeCardholderBilling",
+	52:  "pinData",
+	53:  "securityRelatedControlInformation",
+	54:  "additionalAmounts",
+	55:  "iccData",
+	56:  "reservedIso56",
+	57:  "reservedNational57",
+	58:  "reservedNational58",
+	59:  "reservedNational59",
+	60:  "reservedNational60",
+	61:  "reservedPrivate61",
+	62:  "reservedPrivate62",
+	63:  "reservedPrivate63",
+	64:  "messageAuthenticationCode",
+	65:  "extendedBitmap",
+	66:  "settlementCode",
+	67:  "extendedPaymentCode",
+	68:  "receivingInstitutionCountryCode",
+	69:  "settlementInstitutionCountryCode",
+	70:  "networkManagementInformationCode",
+	71:  "messageNumber",
+	72:  "messageNumberLast",
+	73:  "actionDate",
+	74:  "creditsNumber",
+	75:  "creditsReversalNumber",
+	76:  "debitsNumber",
+	77:  "debitsReversalNumber",
+	78:  "transferNumber",
+	79:  "transferReversalNumber",
+	80:  "inquiriesNumber",
+	81:  "authorizationsNumber",
+	82:  "creditsProcessingFeeAmount",
+	83:  "creditsTransactionFeeAmount",
+	84:  "debitsProcessingFeeAmount",
+	85:  "debitsTransactionFeeAmount",
+	86:  "creditsAmount",
+	87:  "creditsReversalAmount",
+	88:  "debitsAmount",
+	89:  "debitsReversalAmount",
+	90:  "originalDataElements",
+	91:  "fileUpdateCode",
+	92:  "fileSecurityCode",
+	93:  "responseIndicator",
+	94:  "serviceIndicator",
+	95:  "replacementAmounts",
+	96:  "messageSecurityCode",
+	97:  "amountNetSettlement",
+	98:  "payee",
+	99:  "settlementInstitutionId",
+	100: "receivingInstitutionId",
+	101: "fileName",
+	102: "accountId1",
+	103: "accountId2",
+	104: "transactionDescription",
+	105: "reservedIso105",
+	106: "reservedIso106",
+	107: "reservedIso107",
+	108: "reservedIso108",
+	109: "reservedIso109",
+	110: "reservedIso110",
+	111: "reservedIso111",
+	112: "reservedNational112",
+	113: "reservedNational113",
+	114: "reservedNational114",
+	115: "reservedNational115",
+	116: "reservedNational116",
+	117: "reservedNational117",
+	118: "reservedNational118",
+	119: "reservedNational119",
+	120: "reservedPrivate120",
+	121: "reservedPrivate121",
+	122: "reservedPrivate122",
+	123: "reservedPrivate123",
+	124: "reservedPrivate124",
+	125: "reservedPrivate125",
+	126: "reservedPrivate126",
+	127: "reservedPrivate127",
+	128: "messageAuthenticationCode2",
+}
+
+// fieldNames1993 are the ISO 8583:1993 data element names that differ from 1987
+var fieldNames1993 = map[int]string{
+	12: "localTransactionDateTime",
+	22: "posDataCode",
+	24: "functionCode",
+	25: "messageReasonCode",
+	26: "cardAcceptorBusinessCode",
+	39: "actionCode",
+	56: "originalDataElements",
+	90: "reservedIso90",
+}
+
+// setFieldNames names the fields that have no name yet
+func (p *IsoPackager) setFieldNames(names []string) {
+	for bit, name := range names {
+		if p.IsoPackagerConfig[bit].Name == "" {
+			p.IsoPackagerConfig[bit].Name = name
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Package framing reads and writes length prefixed ISO 8583 messages on a stream
+package framing
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	ErrInvalidHeader = errors.New("invalid length header")
+	ErrInvalidLength = errors.New("invalid message length")
+	ErrFrameTooLarge = errors.New("frame exceeds max length")
+)
+
+// Codec reads and writes frames made of a length header followed by the message
+type Codec struct {
+	Header LengthHeader
+	// IncludeHeader is true when the length in the header counts the header bytes too
+	IncludeHeader bool
+	// MaxLength is the max message length accepted, 0 means no limit
+	MaxLength int
+}
+
+// NewCodec creates a codec with the given length header, the length excludes the header
+func NewCodec(header LengthHeader) *Codec {
+	return &Codec{
+		Header: header,
+	}
+}
+
+// ReadFrame reads one complete message from r.
+// Every frame is read into a new buffer, so it can be handed straight to
+// Message.Unpack, which keeps referencing the buffer.
+func (c *Codec) ReadFrame(r io.Reader) ([]byte, error) {
+	var headerBuf [8]byte
+	headerSize := c.Header.Size()
+	if headerSize > len(headerBuf) {
+		return nil, fmt.Errorf("%w: header size %d", ErrInvalidHeader, headerSize)
+	}
+
+	header := headerBuf[:headerSize]
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	length, err := c.Header.Decode(header)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.IncludeHeader {
+		length -= headerSize
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidLength, length)
+	}
+	if c.MaxLength > 0 && length > c.MaxLength {
+		return nil, fmt.Errorf("%w: max %d, got %d", ErrFrameTooLarge, c.MaxLength, length)
+	}
+
+	frame := make([]byte, length)
+	if _, err = io.ReadFull(r, frame); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	return frame, nil
+}
+
+// WriteFrame writes the length header and msg to w in a single write
+func (c *Codec) WriteFrame(w io.Writer, msg []byte) error {
+	if c.MaxLength > 0 && len(msg) > c.MaxLength {
+		return fmt.Errorf("%w: max %d, got %d", ErrFrameTooLarge, c.MaxLength, len(msg))
+	}
+
+	headerSize := c.Header.Size()
+	length := len(msg)
+	if c.IncludeHeader {
+		length += headerSize
+	}
+
+	frame := make([]byte, headerSize+len(msg))
+	if err := c.Header.Encode(frame[:headerSize], length); err != nil {
+		return err
+	}
+	copy(frame[headerSize:], msg)
+
+	_, err := w.Write(frame)
+	return err
+}
@@ -0,0 +1,92 @@
+package framing
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	msg := bytes.Repeat([]byte("A"), 300)
+
+	tests := []struct {
+		name   string
+		codec  *Codec
+		header []byte
+	}{
+		{"binary 2", NewCodec(BinaryHeader(2)), []byte{0x01, 0x2C}},
+		{"binary 4", NewCodec(BinaryHeader(4)), []byte{0x00, 0x00, 0x01, 0x2C}},
+		{"ascii 4", NewCodec(ASCIIHeader(4)), []byte("0300")},
+		{"bcd 2", NewCodec(BCDHeader(2)), []byte{0x03, 0x00}},
+		{"binary 2 including header", &Codec{Header: BinaryHeader(2), IncludeHeader: true}, []byte{0x01, 0x2E}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, tt.codec.WriteFrame(&buf, msg))
+			require.NoError(t, tt.codec.WriteFrame(&buf, msg[:1]))
+			assert.Equal(t, tt.header, buf.Bytes()[:len(tt.header)])
+
+			frame, err := tt.codec.ReadFrame(&buf)
+			require.NoError(t, err)
+			assert.Equal(t, msg, frame)
+
+			frame, err = tt.codec.ReadFrame(&buf)
+			require.NoError(t, err)
+			assert.Equal(t, msg[:1], frame)
+
+			_, err = tt.codec.ReadFrame(&buf)
+			assert.ErrorIs(t, err, io.EOF)
+		})
+	}
+}
+
+func TestCodecReadMalformed(t *testing.T) {
+	tests := []struct {
+		name  string
+		codec *Codec
+		data  []byte
+		err   error
+	}{
+		{"truncated header", NewCodec(BinaryHeader(2)), []byte{0x01}, io.ErrUnexpectedEOF},
+		{"truncated message", NewCodec(BinaryHeader(2)), []byte{0x00, 0x05, 'A'}, io.ErrUnexpectedEOF},
+		{"ascii digit", NewCodec(ASCIIHeader(4)), []byte("00A1"), ErrInvalidHeader},
+		{"bcd nibble", NewCodec(BCDHeader(2)), []byte{0x0A, 0x00}, ErrInvalidHeader},
+		{"shorter than the header", &Codec{Header: BinaryHeader(2), IncludeHeader: true}, []byte{0x00, 0x01}, ErrInvalidLength},
+		{"too large", &Codec{Header: BinaryHeader(2), MaxLength: 10}, []byte{0x00, 0x0B}, ErrFrameTooLarge},
+		{"negative binary 8", NewCodec(BinaryHeader(8)), []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, ErrInvalidLength},
+		{"header over 8 bytes", NewCodec(BinaryHeader(9)), nil, ErrInvalidHeader},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.codec.ReadFrame(bytes.NewReader(tt.data))
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestCodecWriteOverflow(t *testing.T) {
+	tests := []struct {
+		name  string
+		codec *Codec
+		size  int
+		err   error
+	}{
+		{"binary 1", NewCodec(BinaryHeader(1)), 256, ErrInvalidLength},
+		{"ascii 2", NewCodec(ASCIIHeader(2)), 100, ErrInvalidLength},
+		{"bcd 1", NewCodec(BCDHeader(1)), 100, ErrInvalidLength},
+		{"binary 1 including header", &Codec{Header: BinaryHeader(1), IncludeHeader: true}, 255, ErrInvalidLength},
+		{"max length", &Codec{Header: BinaryHeader(2), MaxLength: 10}, 11, ErrFrameTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := tt.codec.WriteFrame(&buf, make([]byte, tt.size))
+			assert.ErrorIs(t, err, tt.err)
+			assert.Zero(t, buf.Len(), "nothing is written on error")
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package framing
+
+import (
+	"fmt"
+)
+
+// LengthHeader encodes and decodes the length header in front of a message
+type LengthHeader interface {
+	// Size returns the number of bytes of the header
+	Size() int
+	// Encode writes n into dst, dst has exactly Size bytes
+	Encode(dst []byte, n int) error
+	// Decode parses the length from src, src has exactly Size bytes
+	Decode(src []byte) (int, error)
+}
+
+// BinaryHeader is a big-endian binary length header of the given number of bytes
+// e.g. BinaryHeader(2) for the common 2 byte header
+type BinaryHeader int
+
+// ASCIIHeader is a decimal ASCII length header of the given number of digits
+// e.g. ASCIIHeader(4) for "0123"
+type ASCIIHeader int
+
+// BCDHeader is a packed BCD length header of the given number of bytes
+// e.g. BCDHeader(2) for 0x01 0x23
+type BCDHeader int
+
+func (h BinaryHeader) Size() int {
+	return int(h)
+}
+
+func (h BinaryHeader) Encode(dst []byte, n int) error {
+	if n < 0 || (h < 8 && n >= 1<<(8*h)) {
+		return fmt.Errorf("%w: %d does not fit in %d byte binary header", ErrInvalidLength, n, h)
+	}
+	for i := len(dst) - 1; i >= 0; i-- {
+		dst[i] = byte(n)
+		n >>= 8
+	}
+	return nil
+}
+
+func (h BinaryHeader) Decode(src []byte) (int, error) {
+	n := 0
+	for _, b := range src {
+		n = n<<8 | int(b)
+	}
+	return n, nil
+}
+
+func (h ASCIIHeader) Size() int {
+	return int(h)
+}
+
+func (h ASCIIHeader) Encode(dst []byte, n int) error {
+	if n < 0 || n >= pow10(int(h)) {
+		return fmt.Errorf("%w: %d does not fit in %d digit header", ErrInvalidLength, n, h)
+	}
+	for i := len(dst) - 1; i >= 0; i-- {
+		dst[i] = byte('0' + n%10)
+		n /= 10
+	}
+	return nil
+}
+
+func (h ASCIIHeader) Decode(src []byte) (int, error) {
+	n := 0
+	for _, c := range src {
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("%w: invalid digit %q", ErrInvalidHeader, c)
+		}
+		n = n*10 + int(c-'0')
+	}
+	return n, nil
+}
+
+func (h BCDHeader) Size() int {
+	return int(h)
+}
+
+func (h BCDHeader) Encode(dst []byte, n int) error {
+	if n < 0 || n >= pow10(int(h)*2) {
+		return fmt.Errorf("%w: %d does not fit in %d byte bcd header", ErrInvalidLength, n, h)
+	}
+	for i := len(dst) - 1; i >= 0; i-- {
+		dst[i] = byte(n%10) | byte((n/10)%10)<<4
+		n /= 100
+	}
+	return nil
+}
+
+func (h BCDHeader) Decode(src []byte) (int, error) {
+	n := 0
+	for _, b := range src {
+		hi, lo := int(b>>4), int(b&0x0F)
+		if hi > 9 || lo > 9 {
+			return 0, fmt.Errorf("%w: invalid bcd byte %#x", ErrInvalidHeader, b)
+		}
+		n = n*100 + hi*10 + lo
+	}
+	return n, nil
+}
+
+func pow10(n int) int {
+	p := 1
+	for i := 0; i < n; i++ {
+		p *= 10
+	}
+	return p
+}
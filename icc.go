@@ -0,0 +1,315 @@
+package iso8583
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pentaly7/iso8583/tlv"
+)
+
+// ICCDataBit is the bit of the ICC (EMV chip) data
+const ICCDataBit = 55
+
+// RuleICC is the rule name of a RuleViolation of an ICCRule
+const RuleICC = "icc"
+
+var (
+	ErrICCMissingTag    = errors.New("icc data missing tag")
+	ErrICCDuplicateTag  = errors.New("icc data duplicate tag")
+	ErrICCTagOrder      = errors.New("icc data tag out of order")
+	ErrICCTagNotAllowed = errors.New("icc data tag not allowed")
+)
+
+// ICCRule defines the EMV tags of the DE 55 of the messages matching the MTI pattern
+type ICCRule struct {
+	// MTI is the MTI or a pattern with x as wildcard digit like MessageRule.MTI,
+	// the most specific matching rule is applied
+	MTI      string
+	Required []uint32
+	// Optional tags are allowed, when set every tag that is not required or optional is rejected
+	Optional []uint32
+	// Ordered requires the required and optional tags in the listed order,
+	// the other tags may be anywhere
+	Ordered bool
+}
+
+// chipTransactionTags are the tags of an authorization or financial request
+// of a chip transaction, required first
+var (
+	chipTransactionTags = []uint32{
+		0x9F26, 0x9F27, 0x9F10, 0x9F37, 0x9F36, 0x95, 0x9A, 0x9C,
+		0x9F02, 0x5F2A, 0x82, 0x9F1A, 0x9F03, 0x9F33,
+	}
+	chipTransactionOptionalTags = []uint32{
+		0x9F34, 0x9F35, 0x9F1E, 0x84, 0x9F09, 0x9F41, 0x5F34, 0x9F53,
+		0x9F6E, 0x9F7C, 0x9F66, 0x9F6C, 0x4F, 0x9F06, 0x9F5B,
+	}
+)
+
+// AddICCRule adds a DE 55 rule to the packager, applied by Message.Validate and
+// Message.SetICCData. Add the rules before the packager is shared.
+func (p *IsoPackager) AddICCRule(rule ICCRule) error {
+	if len(rule.MTI) != len(MTITypeByte{}) {
+		return fmt.Errorf("%w: mti %q", ErrInvalidRule, rule.MTI)
+	}
+	for _, tag := range slices.Concat(rule.Required, rule.Optional) {
+		if err := tlv.ValidateTag(tag); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidRule, err)
+		}
+	}
+	p.iccRules = append(p.iccRules, rule)
+	return nil
+}
+
+// RegisterDefaultICCRules adds the ICC rules of the chip authorization and financial
+// requests 0100, 0200, 1100 and 1200: the EMV cryptogram and terminal data tags are
+// required in order, 9F34, 9F35, 84, 9F1E and the other common tags are optional
+func (p *IsoPackager) RegisterDefaultICCRules() {
+	for _, mti := range []string{"0100", "0200", "1100", "1200"} {
+		p.iccRules = append(p.iccRules, ICCRule{
+			MTI:      mti,
+			Required: slices.Clone(chipTransactionTags),
+			Optional: slices.Clone(chipTransactionOptionalTags),
+			Ordered:  true,
+		})
+	}
+}
+
+// ICCRuleFor returns the most specific ICC rule matching the MTI, nil when there is none
+func (p *IsoPackager) ICCRuleFor(mti MTITypeByte) *ICCRule {
+	var (
+		best          *ICCRule
+		bestWildcards = len(mti) + 1
+	)
+	for i := range p.iccRules {
+		wildcards, ok := matchMtiPattern(p.iccRules[i].MTI, mti)
+		if ok && wildcards < bestWildcards {
+			best, bestWildcards = &p.iccRules[i], wildcards
+		}
+	}
+	return best
+}
+
+// Validate returns every error of the ICC data joined: duplicate, missing,
+// not allowed and out of order tags, and the tags breaking the tlv dictionary
+func (r *ICCRule) Validate(data *tlv.Data) error {
+	return errors.Join(r.validate(data)...)
+}
+
+func (r *ICCRule) validate(data *tlv.Data) (errs []error) {
+	tags := topLevelTags(data)
+
+	seen := make(map[uint32]bool, len(tags))
+	for _, tag := range tags {
+		if seen[tag] {
+			errs = append(errs, fmt.Errorf("%w: %X", ErrICCDuplicateTag, tag))
+		}
+		seen[tag] = true
+	}
+
+	for _, tag := range r.Required {
+		if !seen[tag] {
+			errs = append(errs, fmt.Errorf("%w: %X %s", ErrICCMissingTag, tag, tlv.TagName(tag)))
+		}
+	}
+
+	listed := slices.Concat(r.Required, r.Optional)
+	if r.Optional != nil {
+		for _, tag := range tags {
+			if !slices.Contains(listed, tag) {
+				errs = append(errs, fmt.Errorf("%w: %X", ErrICCTagNotAllowed, tag))
+			}
+		}
+	}
+
+	if r.Ordered {
+		last, lastTag := -1, uint32(0)
+		for _, tag := range tags {
+			i := slices.Index(listed, tag)
+			if i == -1 {
+				continue
+			}
+			if i < last {
+				errs = append(errs, fmt.Errorf("%w: %X after %X", ErrICCTagOrder, tag, lastTag))
+			}
+			last, lastTag = i, tag
+		}
+	}
+
+	if err := data.Validate(); err != nil {
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			return append(errs, joined.Unwrap()...)
+		}
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// order returns the position of the tag in the rule, the tags not listed come last
+func (r *ICCRule) order(tag uint32) int {
+	if i := slices.Index(r.Required, tag); i != -1 {
+		return i
+	}
+	if i := slices.Index(r.Optional, tag); i != -1 {
+		return len(r.Required) + i
+	}
+	return len(r.Required) + len(r.Optional)
+}
+
+// topLevelTags returns the tags of the data in order, without the nested ones
+func topLevelTags(data *tlv.Data) (tags []uint32) {
+	_ = data.Walk(func(_ []uint32, t *tlv.TagData) error {
+		tags = append(tags, t.Tag())
+		return tlv.SkipChildren
+	})
+	return tags
+}
+
+// ICCBuilder assembles the DE 55 ICC data, the tags are written in the order of
+// the rule whatever the order they are added in
+//
+//	data, err := iso8583.NewICCBuilder(packager.ICCRuleFor(msg.MTI)).
+//		Add(0x9F26, cryptogram).
+//		Add(0x9F27, []byte{0x80}).
+//		Build()
+type ICCBuilder struct {
+	rule *ICCRule
+	tags []uint32
+	data map[uint32][]byte
+	err  error
+}
+
+// NewICCBuilder creates a builder, without a rule the tags keep the order they are added in
+// and only the tlv dictionary is checked
+func NewICCBuilder(rule *ICCRule) *ICCBuilder {
+	return &ICCBuilder{
+		rule: rule,
+		data: make(map[uint32][]byte),
+	}
+}
+
+// Add adds the tag, adding a tag twice is an error returned by Build
+func (b *ICCBuilder) Add(tag uint32, value []byte) *ICCBuilder {
+	if _, ok := b.data[tag]; ok {
+		b.err = errors.Join(b.err, fmt.Errorf("%w: %X", ErrICCDuplicateTag, tag))
+		return b
+	}
+	b.tags = append(b.tags, tag)
+	b.data[tag] = value
+	return b
+}
+
+// Build returns the ICC data in the order of the rule once it follows the rule
+func (b *ICCBuilder) Build() (*tlv.Data, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	tags := slices.Clone(b.tags)
+	if b.rule != nil {
+		slices.SortStableFunc(tags, func(x, y uint32) int {
+			return b.rule.order(x) - b.rule.order(y)
+		})
+	}
+
+	data := &tlv.Data{}
+	for _, tag := range tags {
+		if err := data.Append(tag, b.data[tag]); err != nil {
+			return nil, err
+		}
+	}
+
+	if b.rule != nil {
+		return data, b.rule.Validate(data)
+	}
+	return data, data.Validate()
+}
+
+// iccHex reports whether DE 55 holds the ICC data in hex, it does when the field type is b.
+// Other types hold the raw bytes, which must not be translated to EBCDIC or packed as BCD.
+func (p *IsoPackager) iccHex() (bool, error) {
+	config := &p.IsoPackagerConfig[ICCDataBit]
+	if config.Type == BitTypeB {
+		return true, nil
+	}
+	if encoding := p.ValueEncodings[ICCDataBit]; encoding != EncodingASCII {
+		return false, fmt.Errorf("%w: bit %d: raw icc data of type %s needs the ascii value encoding, got %s",
+			ErrInvalidEncoding, ICCDataBit, config.Type, encoding)
+	}
+	return false, nil
+}
+
+// SetICCData validates the ICC data against the ICC rule of the MTI and sets DE 55,
+// in hex when the field type is b, the packed bytes otherwise. The bytes are not
+// translated, so a DE 55 that is not b needs the ascii value encoding.
+func (m *Message) SetICCData(data *tlv.Data) error {
+	isHex, err := m.packager.iccHex()
+	if err != nil {
+		return err
+	}
+
+	if rule := m.packager.ICCRuleFor(m.MTI); rule != nil {
+		err = rule.Validate(data)
+	} else {
+		err = data.Validate()
+	}
+	if err != nil {
+		return err
+	}
+
+	packed := data.Pack()
+	if isHex {
+		m.SetString(ICCDataBit, strings.ToUpper(hex.EncodeToString(packed)))
+		return nil
+	}
+	if packed == nil {
+		packed = []byte{}
+	}
+	m.SetByte(ICCDataBit, packed)
+	return nil
+}
+
+// GetICCData parses DE 55, it returns empty data when the bit is not set
+func (m *Message) GetICCData() (*tlv.Data, error) {
+	isHex, err := m.packager.iccHex()
+	if err != nil {
+		return nil, err
+	}
+
+	value := m.GetByte(ICCDataBit)
+	if isHex {
+		decoded, err := hex.DecodeString(string(value))
+		if err != nil {
+			// the hex error quotes the offending character of the value
+			return nil, fmt.Errorf("%w: bit %d: not hex", ErrInvalidValue, ICCDataBit)
+		}
+		value = decoded
+	}
+
+	data, err := tlv.New(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bit %d: %w", ErrInvalidValue, ICCDataBit, err)
+	}
+	return data, nil
+}
+
+// iccViolations returns a violation for every error of DE 55 against the ICC rule of the MTI,
+// the errors name the tags and never hold their values
+func (m *Message) iccViolations() (violations []*RuleViolation) {
+	rule := m.packager.ICCRuleFor(m.MTI)
+	if rule == nil {
+		return nil
+	}
+
+	data, err := m.GetICCData()
+	if err != nil {
+		return []*RuleViolation{{Bit: ICCDataBit, Rule: RuleICC, Detail: err.Error()}}
+	}
+	for _, err = range rule.validate(data) {
+		violations = append(violations, &RuleViolation{Bit: ICCDataBit, Rule: RuleICC, Detail: err.Error()})
+	}
+	return violations
+}
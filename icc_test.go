@@ -0,0 +1,224 @@
+package iso8583
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/pentaly7/iso8583/tlv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateMalformedICCData(t *testing.T) {
+	tests := []struct {
+		name string
+		de55 []byte
+	}{
+		{"length of 8 bytes", []byte{0x9A, 0x88, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}},
+		{"length of 4 bytes past the end", []byte{0x9A, 0x84, 0x7F, 0xFF, 0xFF, 0xFF}},
+		{"indefinite length", []byte{0x9A, 0x80, 0x25, 0x12, 0x31}},
+		{"truncated length", []byte{0x9F, 0x02, 0x82, 0x01}},
+		{"truncated tag", []byte{0x9F}},
+	}
+	for _, tt := range tests {
+		for name, packager := range map[string]func() *IsoPackager{
+			"1987 raw bytes": DefaultPackager,
+			"1993 hex":       DefaultPackager1993,
+		} {
+			t.Run(tt.name+" "+name, func(t *testing.T) {
+				p := packager()
+				p.RegisterDefaultICCRules()
+
+				msg := NewMessage(p)
+				msg.SetMtiString("0200")
+				if p.IsoPackagerConfig[ICCDataBit].Type == BitTypeB {
+					msg.SetString(ICCDataBit, strings.ToUpper(hex.EncodeToString(tt.de55)))
+				} else {
+					msg.SetByte(ICCDataBit, tt.de55)
+				}
+
+				var report *ValidationReport
+				require.NotPanics(t, func() { report = msg.Validate() })
+
+				var bits []int
+				for _, v := range report.Violations {
+					bits = append(bits, v.Bit)
+				}
+				assert.Contains(t, bits, ICCDataBit)
+
+				_, err := msg.GetICCData()
+				assert.ErrorIs(t, err, ErrInvalidValue)
+			})
+		}
+	}
+}
+
+func TestICCViolationsLeaveValuesOut(t *testing.T) {
+	p := DefaultPackager1993()
+	p.RegisterDefaultICCRules()
+
+	// 5A with an A nibble in the middle, 9F20 with a padding nibble before digits
+	raw := []byte{
+		0x5A, 0x08, 0x41, 0x11, 0x11, 0x11, 0x11, 0x1A, 0x11, 0x11,
+		0x9F, 0x20, 0x03, 0x12, 0xF3, 0x45,
+	}
+	msg := NewMessage(p)
+	msg.SetMtiString("1200")
+	msg.SetString(ICCDataBit, strings.ToUpper(hex.EncodeToString(raw)))
+
+	var details []string
+	for _, v := range msg.Validate().Violations {
+		if v.Bit == ICCDataBit && v.Rule == RuleICC {
+			details = append(details, v.Detail)
+		}
+	}
+	require.NotEmpty(t, details)
+	joined := strings.Join(details, "\n")
+	assert.Contains(t, joined, "tag [5A]")
+	assert.Contains(t, joined, "tag [9F20]")
+	for _, value := range []string{"41 11", "4111", "12 F3", "12F3"} {
+		assert.NotContains(t, joined, value)
+	}
+
+	msg.SetString(ICCDataBit, "5A084111G1")
+	_, err := msg.GetICCData()
+	assert.ErrorIs(t, err, ErrInvalidValue)
+	assert.NotContains(t, err.Error(), "G")
+}
+
+// iccTestRule requires the amount, currency and date in that order and allows the transaction type
+var iccTestRule = ICCRule{
+	MTI:      "0200",
+	Required: []uint32{0x9F02, 0x5F2A, 0x9A},
+	Optional: []uint32{0x9C},
+	Ordered:  true,
+}
+
+var (
+	iccAmount   = []byte{0x00, 0x00, 0x00, 0x00, 0x10, 0x00}
+	iccCurrency = []byte{0x03, 0x60}
+	iccDate     = []byte{0x26, 0x10, 0x18}
+)
+
+func TestICCBuilder(t *testing.T) {
+	rule := iccTestRule
+	data, err := NewICCBuilder(&rule).
+		Add(0x9C, []byte{0x00}).
+		Add(0x9A, iccDate).
+		Add(0x9F02, iccAmount).
+		Add(0x5F2A, iccCurrency).
+		Build()
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{0x9F02, 0x5F2A, 0x9A, 0x9C}, topLevelTags(data), "the tags follow the rule")
+
+	data, err = NewICCBuilder(nil).Add(0x9A, iccDate).Add(0x9F02, iccAmount).Build()
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{0x9A, 0x9F02}, topLevelTags(data), "without a rule the tags keep their order")
+
+	_, err = NewICCBuilder(&rule).Add(0x9F02, iccAmount).Add(0x9F02, iccAmount).Build()
+	assert.ErrorIs(t, err, ErrICCDuplicateTag)
+
+	_, err = NewICCBuilder(&rule).Add(0x9F02, iccAmount).Add(0x9F1E, []byte("12345678")).Build()
+	assert.ErrorIs(t, err, ErrICCMissingTag)
+	assert.ErrorIs(t, err, ErrICCTagNotAllowed)
+}
+
+func TestICCRuleValidate(t *testing.T) {
+	rule := iccTestRule
+
+	data := &tlv.Data{}
+	require.NoError(t, data.Append(0x5F2A, iccCurrency))
+	require.NoError(t, data.Append(0x9F02, iccAmount))
+	require.NoError(t, data.Append(0x9F02, iccAmount))
+	err := rule.Validate(data)
+	assert.ErrorIs(t, err, ErrICCDuplicateTag)
+	assert.ErrorIs(t, err, ErrICCMissingTag)
+	assert.ErrorIs(t, err, ErrICCTagOrder)
+	assert.Contains(t, err.Error(), "icc data tag out of order: 9F02 after 5F2A")
+
+	rule.Optional = nil
+	data = &tlv.Data{}
+	for _, tag := range []uint32{0x9F02, 0x5F2A, 0x9A, 0x9F1E} {
+		value := map[uint32][]byte{0x9F02: iccAmount, 0x5F2A: iccCurrency, 0x9A: iccDate, 0x9F1E: []byte("12345678")}[tag]
+		require.NoError(t, data.Append(tag, value))
+	}
+	assert.NoError(t, rule.Validate(data), "without optional tags every tag is allowed")
+}
+
+func TestAddICCRule(t *testing.T) {
+	p := DefaultPackager()
+	assert.ErrorIs(t, p.AddICCRule(ICCRule{MTI: "020"}), ErrInvalidRule)
+	assert.ErrorIs(t, p.AddICCRule(ICCRule{MTI: "0200", Required: []uint32{0}}), ErrInvalidRule)
+
+	require.NoError(t, p.AddICCRule(ICCRule{MTI: "0xxx"}))
+	require.NoError(t, p.AddICCRule(iccTestRule))
+	assert.Equal(t, "0200", p.ICCRuleFor(MTIType("0200").ToMtiByte()).MTI)
+	assert.Equal(t, "0xxx", p.ICCRuleFor(MTIType("0100").ToMtiByte()).MTI)
+	assert.Nil(t, p.ICCRuleFor(MTIType("1200").ToMtiByte()))
+}
+
+func TestSetICCDataRoundTrip(t *testing.T) {
+	for name, packager := range map[string]func() *IsoPackager{
+		"1987 raw bytes": DefaultPackager,
+		"1993 hex":       DefaultPackager1993,
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := packager()
+			require.NoError(t, p.AddICCRule(iccTestRule))
+
+			msg := NewMessage(p)
+			msg.SetMtiString("0200")
+			data, err := NewICCBuilder(p.ICCRuleFor(msg.MTI)).
+				Add(0x9A, iccDate).
+				Add(0x9F02, iccAmount).
+				Add(0x5F2A, iccCurrency).
+				Build()
+			require.NoError(t, err)
+			require.NoError(t, msg.SetICCData(data))
+
+			out, err := msg.GetICCData()
+			require.NoError(t, err)
+			assert.Equal(t, data.Pack(), out.Pack())
+
+			missing := &tlv.Data{}
+			require.NoError(t, missing.Append(0x9F02, iccAmount))
+			assert.ErrorIs(t, msg.SetICCData(missing), ErrICCMissingTag)
+			out, err = msg.GetICCData()
+			require.NoError(t, err)
+			assert.Equal(t, data.Pack(), out.Pack(), "DE 55 is left as it was")
+		})
+	}
+}
+
+func TestICCDataEncoding(t *testing.T) {
+	data, err := NewICCBuilder(nil).Add(0x9F02, iccAmount).Add(0x5F2A, iccCurrency).Build()
+	require.NoError(t, err)
+
+	for _, encoding := range []string{"ebcdic", "bcd"} {
+		t.Run("b "+encoding, func(t *testing.T) {
+			p := newTestPackager(t, "",
+				`"55": {"type": "b", "length": {"type": "LLLVAR", "max": 510}, "encoding": "`+encoding+`"}`)
+			msg := NewMessage(p)
+			msg.SetMtiString("0200")
+			require.NoError(t, msg.SetICCData(data))
+
+			b, err := msg.PackISO()
+			require.NoError(t, err)
+			out := NewMessage(p)
+			require.NoError(t, out.Unpack(b))
+			icc, err := out.GetICCData()
+			require.NoError(t, err)
+			assert.Equal(t, data.Pack(), icc.Pack())
+		})
+	}
+
+	p := newTestPackager(t, "",
+		`"55": {"type": "ans", "length": {"type": "LLLVAR", "max": 255}, "encoding": "ebcdic"}`)
+	msg := NewMessage(p)
+	msg.SetMtiString("0200")
+	assert.ErrorIs(t, msg.SetICCData(data), ErrInvalidEncoding, "raw bytes are not translated to ebcdic")
+	assert.False(t, msg.HasBit(ICCDataBit))
+	_, err = msg.GetICCData()
+	assert.ErrorIs(t, err, ErrInvalidEncoding)
+}
@@ -25,6 +25,8 @@ func init() {
 }
 
 func initLookupTables() {
+	initEBCDICTables()
+
 	const digits = "0123456789ABCDEF"
 	for b := 0; b < 10000; b++ {
 		if b < 256 {
@@ -51,12 +53,13 @@ func initLookupTables() {
 type (
 	// Message for Component Message
 	Message struct {
-		MTI           MTITypeByte // MTI
-		header        []byte      // iso header
-		isoMessageMap [129][]byte // Get Element of Iso Message in Map
-		activeBits    [129]int
+		MTI           MTITypeByte              // MTI
+		header        []byte                   // iso header
+		isoMessageMap [MaxBitNumber + 1][]byte // Get Element of Iso Message in Map
+		activeBits    [MaxBitNumber + 1]int
 		activeCount   int
 		keyBuffer     [128]byte
+		valueBuffer   []byte // decoded values of non ASCII fields
 		packager      *IsoPackager
 		length        int
 		err           error
@@ -160,20 +163,9 @@ func (m *Message) GetMessageKey() string {
 	return unsafe.String(unsafe.SliceData(m.keyBuffer[:]), pos)
 }
 
+// GetMTIResponse returns the response MTI of the message MTI
 func (m *Message) GetMTIResponse() (mti MTITypeByte, err error) {
-	switch {
-	case m.MTI.Equal(MTICardProcessingRequestByte):
-		mti = MTICardProcessingResponseByte
-	case m.MTI.Equal(MTIFinancialRequestByte):
-		mti = MTIFinancialResponseByte
-	case m.MTI.Equal(MTIReversalRequestByte), m.MTI.Equal(MTIRepeatedReversalRequestByte):
-		mti = MTIReversalResponseByte
-	case m.MTI.Equal(MTINMMRequestByte):
-		mti = MTINMMResponseByte
-	default:
-		return mti, ErrNotDefaultMti
-	}
-	return mti, nil
+	return m.MTI.Response()
 }
 
 // SetMTIResponse is set MTI Response for Response Message
@@ -186,59 +178,34 @@ func (m *Message) SetMTIResponse() error {
 	return nil
 }
 
-// IsRequest for check MTI is Request or Response
+// IsRequest for check MTI expects a response (request, advice, notification or instruction)
 func (m *Message) IsRequest() bool {
-	switch {
-	case m.MTI.Equal(MTICardProcessingRequestByte),
-		m.MTI.Equal(MTIFinancialRequestByte),
-		m.MTI.Equal(MTIReversalRequestByte),
-		m.MTI.Equal(MTIRepeatedReversalRequestByte),
-		m.MTI.Equal(MTINMMRequestByte):
-		return true
-	default:
-		return false
-	}
+	return m.MTI.IsRequest()
 }
 
-// IsTransactional for check MTI message is Transaction or NMM
+// IsTransactional for check MTI message is authorization, financial or reversal
 func (m *Message) IsTransactional() bool {
-	switch {
-	case m.MTI.Equal(MTICardProcessingRequestByte),
-		m.MTI.Equal(MTICardProcessingResponseByte),
-		m.MTI.Equal(MTIFinancialRequestByte),
-		m.MTI.Equal(MTIFinancialResponseByte),
-		m.MTI.Equal(MTIReversalRequestByte),
-		m.MTI.Equal(MTIReversalResponseByte),
-		m.MTI.Equal(MTIRepeatedReversalRequestByte):
+	switch m.MTI.Class() {
+	case MTIClassAuthorization, MTIClassFinancial, MTIClassReversal:
 		return true
 	default:
 		return false
 	}
+}
 
+// IsReversal for check MTI message is Reversal
+func (m *Message) IsReversal() bool {
+	return m.MTI.Class() == MTIClassReversal
 }
 
-// IsNMM for check MTI message is Transaction or NMM
+// IsNMM for check MTI message is Network Management
 func (m *Message) IsNMM() bool {
-	switch {
-	case m.MTI.Equal(MTINMMRequestByte),
-		m.MTI.Equal(MTINMMResponseByte):
-		return true
-	default:
-		return false
-	}
+	return m.MTI.Class() == MTIClassNetworkManagement
 }
 
 // IsResponse for check MTI is Request or Response
 func (m *Message) IsResponse() bool {
-	switch {
-	case m.MTI.Equal(MTICardProcessingResponseByte),
-		m.MTI.Equal(MTIFinancialResponseByte),
-		m.MTI.Equal(MTIReversalResponseByte),
-		m.MTI.Equal(MTINMMResponseByte):
-		return true
-	default:
-		return false
-	}
+	return m.MTI.IsResponse()
 }
 
 // ClearEntries for clear all entries so this message can be reused
@@ -248,6 +215,7 @@ func (m *Message) ClearEntries() {
 		m.isoMessageMap[m.activeBits[i]] = nil
 	}
 	m.activeCount = 0
+	m.valueBuffer = m.valueBuffer[:0]
 }
 
 // CreateResponseISO create response ISO Message
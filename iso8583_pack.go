@@ -6,6 +6,9 @@ import (
 	"sort"
 )
 
+// tertiaryBitmapBit is the bit that signals a tertiary bitmap when the packager has one
+const tertiaryBitmapBit = 65
+
 var EmptyBitmap [8]byte
 var EmptyMti [4]byte
 
@@ -24,14 +27,18 @@ func (m *Message) PackISO() ([]byte, error) {
 	}
 	dataLength += len(m.MTI)
 
-	//bitmap := make([]byte, 16) // max 128 bits = 16 bytes
+	//bitmap := make([]byte, 24) // max 192 bits = 24 bytes
 
-	bitmap := [16]byte{}
+	bitmap := [24]byte{}
 
 	//for i, v := range m.isoMessageMap {
 	for i := 0; i < m.activeCount; i++ {
 		bit := m.activeBits[i]
 
+		if bit == tertiaryBitmapBit && m.packager.HasTertiaryBitmap {
+			return nil, fmt.Errorf("%w: bit %d is the tertiary bitmap indicator", ErrInvalidBitNumber, bit)
+		}
+
 		length, err := m.getTotalBitLength(bit)
 		if err != nil {
 			return nil, err
@@ -49,21 +56,32 @@ func (m *Message) PackISO() ([]byte, error) {
 		bitmap[byteIndex] |= 1 << (7 - bitIndex)
 	}
 
-	dataLength += BitmapLength
+	bitmapLength := m.packager.bitmapLength()
+	dataLength += bitmapLength
+
+	// check third bitmap
+	if !bytes.Equal(bitmap[16:], EmptyBitmap[:]) {
+		if !m.packager.HasTertiaryBitmap {
+			return nil, fmt.Errorf("%w: bits above %d need a tertiary bitmap", ErrInvalidBitNumber, maxSecondaryBitNumber)
+		}
+		// set bit 65 to indicate third bitmap is on
+		bitmap[8] |= 0x80
+		dataLength += bitmapLength
+	}
 
 	// check second bitmap
-	if !bytes.Equal(bitmap[8:], EmptyBitmap[:]) {
+	if !bytes.Equal(bitmap[8:16], EmptyBitmap[:]) {
 		// set first bit to indicate second bitmap is on
 		// 0x80 is 10000000, and use OR operation
 		bitmap[0] |= 0x80
-		dataLength += BitmapLength
+		dataLength += bitmapLength
 	}
 
 	return m.processPackIso(bitmap, dataLength)
 
 }
 
-func (m *Message) processPackIso(bitmap [16]byte, dataLength int) ([]byte, error) {
+func (m *Message) processPackIso(bitmap [24]byte, dataLength int) ([]byte, error) {
 
 	byteData := make([]byte, dataLength)
 
@@ -77,24 +95,29 @@ func (m *Message) processPackIso(bitmap [16]byte, dataLength int) ([]byte, error
 	}
 
 	// MTI
-	pos += copy(byteData[pos:], m.MTI[:])
-	// byteData = append(byteData, m.MTI[:]...)
+	if m.packager.MTIEncoding.isEBCDIC() {
+		pos += asciiToEBCDIC(byteData[pos:], m.MTI[:], m.packager.MTIEncoding)
+	} else {
+		pos += copy(byteData[pos:], m.MTI[:])
+	}
 
 	// --- First bitmap directly into byteData ---
 	// for _, b := range bitmap[:8] {
 	// 	byteData = append(byteData, hexTable[b][0], hexTable[b][1])
 	// }
-	encodeHexUpper(byteData[pos:], bitmap[:8])
-	pos += BitmapLength
+	pos += m.encodeBitmapInto(byteData[pos:], bitmap[:8])
 
 	// --- Second bitmap if exists ---
 	if bitmap[0]&0x80 != 0 {
 		// for _, b := range bitmap[8:] {
 		// 	byteData = append(byteData, hexTable[b][0], hexTable[b][1])
 		// }
-		encodeHexUpper(byteData[pos:], bitmap[8:])
-		pos += BitmapLength
+		pos += m.encodeBitmapInto(byteData[pos:], bitmap[8:16])
+	}
 
+	// --- Third bitmap if exists ---
+	if bitmap[8]&0x80 != 0 && m.packager.HasTertiaryBitmap {
+		pos += m.encodeBitmapInto(byteData[pos:], bitmap[16:])
 	}
 
 	// --- Fields ---
@@ -117,17 +140,37 @@ func (m *Message) processPackIso(bitmap [16]byte, dataLength int) ([]byte, error
 					length,
 				)
 			}
-			encodeLenInto(length, prefixLen, byteData[pos:pos+prefixLen])
-			pos += prefixLen
+			prefixSize := m.packager.PrefixSizes[bitNum]
+			err := encodeLenInto(length, prefixLen, m.packager.LengthEncodings[bitNum], byteData[pos:pos+prefixSize])
+			if err != nil {
+				return nil, fmt.Errorf("bit %d: %w", bitNum, err)
+			}
+			pos += prefixSize
 		}
 
-		pos += copy(byteData[pos:], value)
-		// byteData = append(byteData, value...)
+		n, err := m.encodeValueInto(bitNum, value, byteData[pos:])
+		if err != nil {
+			return nil, err
+		}
+		pos += n
 	}
 
 	return byteData, nil
 }
 
+// encodeBitmapInto writes a single 8 byte bitmap into dst using the packager
+// bitmap encoding and returns the number of bytes written.
+func (m *Message) encodeBitmapInto(dst []byte, bitmap []byte) int {
+	if m.packager.BitmapEncoding == EncodingBinary {
+		return copy(dst, bitmap)
+	}
+	encodeHexUpper(dst, bitmap)
+	if m.packager.BitmapEncoding.isEBCDIC() {
+		asciiToEBCDIC(dst[:BitmapLength], dst[:BitmapLength], m.packager.BitmapEncoding)
+	}
+	return BitmapLength
+}
+
 // encodeHexUpper encodes the source byte slice into hexadecimal representation
 // and stores the result in the destination byte slice.
 //
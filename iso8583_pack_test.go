@@ -0,0 +1,167 @@
+package iso8583
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bitmapTestFields are the fields of the bitmap tests, DE 70 needs a secondary bitmap
+var bitmapTestFields = []string{
+	`"2": {"type": "n", "length": {"type": "LLVAR", "max": 19}}`,
+	`"3": {"type": "n", "length": {"type": "FIXED", "max": 6}}`,
+	`"70": {"type": "n", "length": {"type": "FIXED", "max": 3}}`,
+}
+
+func TestBinaryBitmapRoundTrip(t *testing.T) {
+	packager := newTestPackager(t, `"bitmapEncoding": "binary",`, bitmapTestFields...)
+
+	tests := []struct {
+		name   string
+		fields map[int]string
+		want   []byte
+	}{
+		{
+			"primary",
+			map[int]string{2: "4111111111111111", 3: "000000"},
+			append([]byte("0200\x60\x00\x00\x00\x00\x00\x00\x00"), "164111111111111111000000"...),
+		},
+		{
+			"secondary",
+			map[int]string{3: "000000", 70: "301"},
+			append([]byte("0200\xA0\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x00\x00\x00\x00"), "000000301"...),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := NewMessage(packager)
+			msg.SetMtiString("0200")
+			for bit, value := range tt.fields {
+				msg.SetString(bit, value)
+			}
+
+			b, err := msg.PackISO()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, b)
+
+			out := NewMessage(packager)
+			require.NoError(t, out.Unpack(b))
+			for bit, value := range tt.fields {
+				assert.Equal(t, value, out.GetString(bit), "bit %d", bit)
+			}
+		})
+	}
+}
+
+func TestBinaryBitmapUnpackMalformed(t *testing.T) {
+	packager := newTestPackager(t, `"bitmapEncoding": "binary",`, bitmapTestFields...)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"short primary bitmap", []byte("0200\x60\x00\x00")},
+		{"missing secondary bitmap", []byte("0200\xA0\x00\x00\x00\x00\x00\x00\x00\x04\x00")},
+		{"bit not in the packager", []byte("0200\x08\x00\x00\x00\x00\x00\x00\x00")},
+		{"short field", append([]byte("0200\x60\x00\x00\x00\x00\x00\x00\x00"), "1641111"...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			require.NotPanics(t, func() { err = NewMessage(packager).Unpack(tt.data) })
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestTertiaryBitmapRoundTrip(t *testing.T) {
+	packager := newTestPackager(t, `"hasTertiaryBitmap": true,`,
+		`"3": {"type": "n", "length": {"type": "FIXED", "max": 6}}`,
+		`"70": {"type": "n", "length": {"type": "FIXED", "max": 3}}`,
+		`"130": {"type": "ans", "length": {"type": "LLVAR", "max": 20}}`,
+	)
+
+	tests := []struct {
+		name   string
+		fields map[int]string
+		want   string
+	}{
+		{"secondary only", map[int]string{3: "000000", 70: "301"}, "0200A0000000000000000400000000000000000000301"},
+		{"tertiary", map[int]string{3: "000000", 130: "TERTIARY"}, "0200A00000000000000080000000000000004000000000000000000000" + "08TERTIARY"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := NewMessage(packager)
+			msg.SetMtiString("0200")
+			for bit, value := range tt.fields {
+				msg.SetString(bit, value)
+			}
+
+			b, err := msg.PackISO()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(b))
+
+			out := NewMessage(packager)
+			require.NoError(t, out.UnpackString(tt.want))
+			for bit, value := range tt.fields {
+				assert.Equal(t, value, out.GetString(bit), "bit %d", bit)
+			}
+			assert.False(t, out.HasBit(65), "bit 65 only signals the tertiary bitmap")
+		})
+	}
+}
+
+func TestTertiaryBitmapMalformed(t *testing.T) {
+	_, err := NewPackager(strings.NewReader(`{"packagerConfig": {"130": {"type": "ans", "length": {"type": "LLVAR", "max": 20}}}}`))
+	assert.ErrorIs(t, err, ErrInvalidBitNumber, "bit 130 needs a tertiary bitmap")
+
+	packager := newTestPackager(t, `"hasTertiaryBitmap": true,`,
+		`"130": {"type": "ans", "length": {"type": "LLVAR", "max": 20}}`,
+	)
+	err = NewMessage(packager).UnpackString("0200" + "8000000000000000" + "8000000000000000" + "40000000")
+	assert.ErrorIs(t, err, ErrInsufficientDataBitmap)
+}
+
+func TestDefaultPackager1993RoundTrip(t *testing.T) {
+	for mti, packager := range map[MTIType]func() *IsoPackager{
+		"1200": DefaultPackager1993,
+		"2200": DefaultPackager2003,
+	} {
+		t.Run(string(mti), func(t *testing.T) {
+			msg := NewMessage(packager())
+			msg.SetMtiString(mti)
+			msg.SetString(2, "4111111111111111").
+				SetString(3, "000000").
+				SetString(4, "000000001000").
+				SetString(7, "1018120000").
+				SetString(11, "000123").
+				SetString(12, "261018120000").
+				SetString(24, "200").
+				SetString(37, "000000000123").
+				SetString(41, "TERM0001")
+			require.True(t, msg.Validate().Valid())
+
+			b, err := msg.PackISO()
+			require.NoError(t, err)
+			assert.Equal(t, string(mti), string(b[:4]))
+
+			out := NewMessage(packager())
+			require.NoError(t, out.Unpack(b))
+			assert.Equal(t, mti.ToMtiByte(), out.MTI)
+			assert.Equal(t, "261018120000", out.GetString(12), "DE 12 holds the date and the time")
+			assert.Equal(t, "200", out.GetString(24))
+
+			res, err := CreateResponseISO(out, ActionCodeApproved)
+			require.NoError(t, err)
+			assert.True(t, res.IsResponse())
+			assert.Equal(t, ActionCodeApproved, res.GetString(39))
+			_, err = res.PackISO()
+			require.NoError(t, err)
+
+			out.SetString(12, "1018120000")
+			assert.Equal(t, RuleLength, out.validateBit(12).Rule, "the 1987 DE 12 is too short")
+		})
+	}
+}
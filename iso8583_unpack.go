@@ -8,7 +8,10 @@ import (
 	"unsafe"
 )
 
-const BitmapLength = 16
+const (
+	BitmapLength       = 16 // hex encoded bitmap length
+	BinaryBitmapLength = 8  // binary bitmap length
+)
 
 func (m *Message) UnpackString(s string) error {
 	// convert string to byte array without allocating
@@ -39,14 +42,17 @@ func (m *Message) Unpack(b []byte) error {
 	}
 
 	// check MTI
-	mti := MTITypeByte(b[0:4])
-	if !isValidMti(mti) {
+	mti := MTITypeByte(b[cursor : cursor+4])
+	if m.packager.MTIEncoding.isEBCDIC() {
+		ebcdicToASCII(mti[:], mti[:], m.packager.MTIEncoding)
+	}
+	if !m.packager.IsAllowedMti(mti) {
 		return ErrNotDefaultMti
 	}
 	m.MTI = mti
 	cursor += 4
 
-	if len(b[cursor:]) < BitmapLength {
+	if len(b[cursor:]) < m.packager.bitmapLength() {
 		return ErrInsufficientDataFirstBitmap
 	}
 
@@ -57,43 +63,53 @@ func (m *Message) Unpack(b []byte) error {
 	return nil
 }
 func (m *Message) parseBitmap(b []byte, cursor int) error {
+	bitmapLength := m.packager.bitmapLength()
+
 	// Ensure enough data for at least a primary bitmap
-	if len(b[cursor:]) < BitmapLength {
-		return errors.Join(fmt.Errorf("insufficient data for bitmap: need %d, have %d", BitmapLength, len(b[cursor:])), ErrInsufficientDataBitmap)
+	if len(b[cursor:]) < bitmapLength {
+		return errors.Join(fmt.Errorf("insufficient data for bitmap: need %d, have %d", bitmapLength, len(b[cursor:])), ErrInsufficientDataBitmap)
 	}
 
 	// ----- parse primary bitmap -----
-	// asciiHex is a slice of the hex characters in b (no allocation)
-	asciiHex := b[cursor : cursor+BitmapLength]
+	bitmap := [24]byte{}
 
-	// get a buffer from pool and decode into it
-	bitmap := [16]byte{}
-
-	// bmpBuf has length 8 (as constructed above). Use that directly.
-	if _, err := hex.Decode(bitmap[:8], asciiHex); err != nil {
-		return ErrInvalidBitMap
+	if err := m.decodeBitmapInto(bitmap[:8], b[cursor:cursor+bitmapLength]); err != nil {
+		return err
 	}
 
-	cursor += BitmapLength
+	cursor += bitmapLength
 
 	maxBits := 8
 	// If bit 1 (first bit) is set, there is a secondary bitmap to parse later.
 	if bitmap[0]&(0x80) != 0 { // bit index 0 -> bit 1
-		if len(b[cursor:]) < BitmapLength {
-			return errors.Join(fmt.Errorf("insufficient data for second bitmap: need %d, have %d", BitmapLength, len(b[cursor:])), ErrInsufficientDataBitmap)
+		if len(b[cursor:]) < bitmapLength {
+			return errors.Join(fmt.Errorf("insufficient data for second bitmap: need %d, have %d", bitmapLength, len(b[cursor:])), ErrInsufficientDataBitmap)
 		}
 
-		asciiHex2 := b[cursor : cursor+BitmapLength]
-
-		if _, err := hex.Decode(bitmap[8:], asciiHex2); err != nil {
-			return ErrInvalidBitMap
+		if err := m.decodeBitmapInto(bitmap[8:16], b[cursor:cursor+bitmapLength]); err != nil {
+			return err
 		}
-		cursor += BitmapLength
+		cursor += bitmapLength
 		maxBits = 16
 		// flip the bit 1
 		bitmap[0] &= 0x7F
 	}
 
+	// If bit 65 is set and the packager uses it, there is a tertiary bitmap.
+	if m.packager.HasTertiaryBitmap && bitmap[8]&0x80 != 0 {
+		if len(b[cursor:]) < bitmapLength {
+			return errors.Join(fmt.Errorf("insufficient data for third bitmap: need %d, have %d", bitmapLength, len(b[cursor:])), ErrInsufficientDataBitmap)
+		}
+
+		if err := m.decodeBitmapInto(bitmap[16:], b[cursor:cursor+bitmapLength]); err != nil {
+			return err
+		}
+		cursor += bitmapLength
+		maxBits = 24
+		// flip the bit 65
+		bitmap[8] &= 0x7F
+	}
+
 	// Process primary bitmap bits
 	for byteIdx := 0; byteIdx < maxBits; byteIdx++ {
 		v := bitmap[byteIdx]
@@ -105,7 +121,7 @@ func (m *Message) parseBitmap(b []byte, cursor int) error {
 		for i := 0; i < count; i++ {
 			bitNum := byteIdx*8 + bitPositions[v][i]
 
-			length, prefixLen, err := m.parseBitLength(b, bitNum, cursor)
+			length, prefixSize, err := m.parseBitLength(b, bitNum, cursor)
 			if err != nil {
 				return err
 			}
@@ -113,14 +129,18 @@ func (m *Message) parseBitmap(b []byte, cursor int) error {
 				continue
 			}
 
-			cursor += prefixLen
-			if len(b[cursor:]) < length {
-				msg := fmt.Errorf("insufficient data for bit %d: need %d, have %d", bitNum, length, len(b[cursor:]))
+			cursor += prefixSize
+			size := m.packager.valueSize(bitNum, length)
+			if len(b[cursor:]) < size {
+				msg := fmt.Errorf("insufficient data for bit %d: need %d, have %d", bitNum, size, len(b[cursor:]))
 				return errors.Join(msg, ErrInsufficientDataBitmap)
 			}
 
-			value := b[cursor : cursor+length]
-			cursor += length
+			value, err := m.decodeValue(bitNum, b[cursor:cursor+size], length)
+			if err != nil {
+				return err
+			}
+			cursor += size
 			m.isoMessageMap[bitNum] = value
 			m.appendBit(bitNum)
 		}
@@ -129,3 +149,22 @@ func (m *Message) parseBitmap(b []byte, cursor int) error {
 
 	return nil
 }
+
+// decodeBitmapInto decodes a single bitmap from src into the 8 byte dst
+// using the packager bitmap encoding.
+func (m *Message) decodeBitmapInto(dst []byte, src []byte) error {
+	if m.packager.BitmapEncoding == EncodingBinary {
+		copy(dst, src)
+		return nil
+	}
+	if m.packager.BitmapEncoding.isEBCDIC() {
+		var asciiHex [BitmapLength]byte
+		ebcdicToASCII(asciiHex[:], src, m.packager.BitmapEncoding)
+		src = asciiHex[:]
+	}
+	// src is a slice of the hex characters in b (no allocation)
+	if _, err := hex.Decode(dst, src); err != nil {
+		return ErrInvalidBitMap
+	}
+	return nil
+}
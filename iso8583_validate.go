@@ -6,70 +6,218 @@ import (
 	"fmt"
 )
 
-// ValidateMandatoryBits validate ISO Message
-func (m *Message) ValidateMandatoryBits() error {
-	if m.MTI.Equal(MTINMMRequestByte) || m.MTI.Equal(MTINMMResponseByte) {
-		mandatoryBits := []int{7, 11, 70}
-		for _, bit := range mandatoryBits {
-			if ok := m.HasBit(bit); ok == false {
-				return fmt.Errorf("missing mandatory bit %d", bit)
-			}
+// Rule names of the type, length and charset checks of a RuleViolation
+const (
+	RuleType    = "type"
+	RuleLength  = "length"
+	RuleCharset = "charset"
+)
+
+// Response codes (DE 39) of a ValidationReport, ISO 8583:1987 and the 1993 action codes
+const (
+	ResponseCodeApproved          = "00"
+	ResponseCodeInvalidAmount     = "13"
+	ResponseCodeInvalidCardNumber = "14"
+	ResponseCodeFormatError       = "30"
+
+	ActionCodeApproved          = "000"
+	ActionCodeInvalidAmount     = "110"
+	ActionCodeInvalidCardNumber = "111"
+	ActionCodeFormatError       = "904"
+)
+
+// ValidationReport lists every bit of a message that breaks a rule
+type ValidationReport struct {
+	MTI        MTITypeByte
+	Violations []*RuleViolation
+}
+
+// Valid reports whether the message has no violation
+func (r *ValidationReport) Valid() bool {
+	return len(r.Violations) == 0
+}
+
+// Err returns every violation joined, nil when the message is valid
+func (r *ValidationReport) Err() (err error) {
+	for _, v := range r.Violations {
+		err = errors.Join(err, v)
+	}
+	return err
+}
+
+// ResponseCode returns the DE 39 of the response to the message: approved when valid,
+// invalid card number for DE 2, invalid amount for DE 4 and format error otherwise.
+// ISO 8583:1993 and 2003 messages get the 3 digit action code.
+func (r *ValidationReport) ResponseCode() string {
+	var cardNumber, amount bool
+	for _, v := range r.Violations {
+		switch v.Bit {
+		case 2:
+			cardNumber = true
+		case 4:
+			amount = true
 		}
-		if m.MTI.Equal(MTINMMRequestByte) {
-			return nil
+	}
+
+	is1993 := r.MTI.Version().is1993()
+	switch {
+	case r.Valid():
+		return responseCode(is1993, ActionCodeApproved, ResponseCodeApproved)
+	case cardNumber:
+		return responseCode(is1993, ActionCodeInvalidCardNumber, ResponseCodeInvalidCardNumber)
+	case amount:
+		return responseCode(is1993, ActionCodeInvalidAmount, ResponseCodeInvalidAmount)
+	default:
+		return responseCode(is1993, ActionCodeFormatError, ResponseCodeFormatError)
+	}
+}
+
+// responseCode returns the action code for ISO 8583:1993 and 2003, the response code otherwise
+func responseCode(is1993 bool, actionCode, code string) string {
+	if is1993 {
+		return actionCode
+	}
+	return code
+}
+
+// Validate checks the mandatory rules, type, length, charset and registered validators
+// of every bit and the DE 55 ICC rule in one pass.
+// A failed message can be declined with CreateResponseISO(msg, report.ResponseCode()).
+func (m *Message) Validate() *ValidationReport {
+	report := &ValidationReport{MTI: m.MTI}
+	report.Violations = m.mandatoryViolations()
+
+	for i := 0; i < m.activeCount; i++ {
+		bit := m.activeBits[i]
+		if v := m.validateBit(bit); v != nil {
+			report.Violations = append(report.Violations, v)
+			continue
 		}
-		if ok := m.HasBit(39); ok == false {
-			return fmt.Errorf("missing mandatory bit 39 for response")
+		report.Violations = append(report.Violations, m.runValidators(bit)...)
+		if bit == ICCDataBit {
+			report.Violations = append(report.Violations, m.iccViolations()...)
 		}
-		return nil
 	}
 
-	for _, bit := range m.packager.MandatoryBit {
-		if ok := m.HasBit(bit); ok == false {
-			return fmt.Errorf("missing mandatory bit %d", bit)
+	for _, v := range report.Violations {
+		if v.Value == "" && m.HasBit(v.Bit) {
+			v.Value, _, _ = m.redactedValue(v.Bit) // masked without a hash key
 		}
 	}
+	return report
+}
 
-	if m.MTI.Equal(MTIReversalRequestByte) ||
-		m.MTI.Equal(MTIReversalResponseByte) ||
-		m.MTI.Equal(MTIRepeatedReversalRequestByte) {
-		if ok := m.HasBit(90); ok == false {
-			return fmt.Errorf("missing mandatory bit 90 for reversal")
-		}
+// ValidateMandatoryBits validate ISO Message and returns every missing or forbidden bit joined.
+// When the packager has a rule for the MTI the rule is applied, otherwise the
+// isMandatory fields and the network management and reversal bits of the MTI version.
+func (m *Message) ValidateMandatoryBits() (err error) {
+	for _, v := range m.mandatoryViolations() {
+		err = errors.Join(err, v)
 	}
+	return err
+}
 
-	return nil
+// ValidateRule returns every violation of the rule joined, nil when the message follows it
+func (m *Message) ValidateRule(rule *MessageRule) (err error) {
+	for _, v := range rule.Validate(m) {
+		err = errors.Join(err, v)
+	}
+	return err
 }
 
+// ValidateBitType checks the charset of every set bit against its type
 func (m *Message) ValidateBitType() (err error) {
-	for bit, v := range m.isoMessageMap {
+	for i := 0; i < m.activeCount; i++ {
+		bit := m.activeBits[i]
 		bitType := m.packager.IsoPackagerConfig[bit].Type
-		val := string(v)
-		switch bitType {
-		case BitTypeN:
-			if !reNumeric.MatchString(val) {
-				err = errors.Join(err, ErrInvalidValue, fmt.Errorf("invalid type bit %d type %s got %s", bit, bitType, val))
-			}
-		case BitTypeAN:
-			if !reAlphaNum.MatchString(val) {
-				err = errors.Join(err, ErrInvalidValue, fmt.Errorf("invalid type bit %d type %s got %s", bit, bitType, val))
-			}
-		case BitTypeANS:
-			// accept everything
-			return nil
-		case BitTypeB:
-			_, errConv := hex.DecodeString(val)
-			if errConv != nil {
-				err = errors.Join(err, ErrInvalidValue, fmt.Errorf("invalid type bit %d type %s got %s", bit, bitType, val))
-			}
-		case BitTypeZ:
-			if !reTrackData.MatchString(val) {
-				err = errors.Join(err, ErrInvalidValue, fmt.Errorf("invalid type bit %d type %s got %s", bit, bitType, val))
-			}
-		default:
-			err = errors.Join(err, ErrInvalidBitType)
+		if !validCharset(bitType, m.isoMessageMap[bit]) {
+			value, _, _ := m.redactedValue(bit) // masked without a hash key
+			err = errors.Join(err, ErrInvalidValue, fmt.Errorf("invalid type bit %d type %s got %s", bit, bitType, value))
 		}
 	}
-
 	return err
 }
+
+func (m *Message) mandatoryViolations() []*RuleViolation {
+	if rule := m.packager.RuleFor(m.MTI); rule != nil {
+		return rule.Validate(m)
+	}
+
+	var violations []*RuleViolation
+	missing := func(bit int, detail string) {
+		if !m.HasBit(bit) {
+			violations = append(violations, &RuleViolation{Bit: bit, Rule: RuleMandatory, Detail: detail})
+		}
+	}
+
+	if m.IsNMM() {
+		for _, bit := range []int{7, 11, m.MTI.Version().NetworkManagementCodeBit()} {
+			missing(bit, "missing")
+		}
+		if m.IsResponse() {
+			missing(39, "missing for response")
+		}
+		return violations
+	}
+
+	for _, bit := range m.packager.MandatoryBit {
+		// bit 1 is the bitmap, it is never set as a field
+		if bit == 1 {
+			continue
+		}
+		missing(bit, "missing")
+	}
+
+	if m.IsReversal() {
+		missing(m.MTI.Version().OriginalDataElementsBit(), "missing for reversal")
+	}
+
+	return violations
+}
+
+// validateBit checks the bit is defined in the packager and its value
+// matches the length and charset of its type and subfields
+func (m *Message) validateBit(bit int) *RuleViolation {
+	prefixLen := m.packager.PrefixLengths[bit]
+	if prefixLen == 0 {
+		return &RuleViolation{Bit: bit, Rule: RuleType, Detail: "not defined in packager"}
+	}
+
+	value := m.isoMessageMap[bit]
+	maxLength := m.packager.MaxLengths[bit]
+	if prefixLen == FixedLength && len(value) != maxLength {
+		return &RuleViolation{Bit: bit, Rule: RuleLength, Detail: fmt.Sprintf("fixed %d, got %d", maxLength, len(value))}
+	}
+	if len(value) > maxLength {
+		return &RuleViolation{Bit: bit, Rule: RuleLength, Detail: fmt.Sprintf("max %d, got %d", maxLength, len(value))}
+	}
+
+	bitType := m.packager.IsoPackagerConfig[bit].Type
+	if !validCharset(bitType, value) {
+		return &RuleViolation{Bit: bit, Rule: RuleCharset, Detail: fmt.Sprintf("not %s", bitType)}
+	}
+
+	if subfields := m.packager.IsoPackagerConfig[bit].Subfields; subfields != nil {
+		return subfields.validate(bit, m.GetString(bit))
+	}
+	return nil
+}
+
+func validCharset(bitType BitType, value []byte) bool {
+	switch bitType {
+	case BitTypeN:
+		return reNumeric.Match(value)
+	case BitTypeAN:
+		return reAlphaNum.Match(value)
+	case BitTypeANS:
+		// accept everything
+		return true
+	case BitTypeB:
+		_, err := hex.DecodeString(string(value))
+		return err == nil
+	case BitTypeZ:
+		return reTrackData.Match(value)
+	default:
+		return false
+	}
+}
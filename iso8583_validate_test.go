@@ -0,0 +1,96 @@
+package iso8583
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateReportsEveryViolation(t *testing.T) {
+	msg := newTestRequest("000123")
+	msg.Unset(11)
+	msg.SetString(2, "41111111111A1111")        // not numeric
+	msg.SetString(3, "0000")                    // fixed 6
+	msg.SetString(41, "TERM0001")               // fixed 16
+	msg.SetString(150, "undefined in packager") // no tertiary bitmap
+
+	report := msg.Validate()
+	require.False(t, report.Valid())
+
+	type violation struct {
+		bit  int
+		rule string
+	}
+	var got []violation
+	for _, v := range report.Violations {
+		got = append(got, violation{v.Bit, v.Rule})
+	}
+	assert.ElementsMatch(t, []violation{
+		{11, RuleMandatory},
+		{2, RuleCharset},
+		{3, RuleLength},
+		{41, RuleLength},
+		{150, RuleType},
+	}, got)
+
+	for _, v := range report.Violations {
+		if v.Bit == 2 {
+			assert.Equal(t, "411111******1111", v.Value, "the offending PAN is redacted")
+		}
+		if v.Bit == 41 {
+			assert.Equal(t, "TERM0001", v.Value)
+		}
+	}
+
+	err := report.Err()
+	assert.ErrorIs(t, err, ErrRuleViolation)
+	assert.Contains(t, err.Error(), "bit 11 mandatory: missing")
+	assert.Contains(t, err.Error(), "bit 2 charset: not n")
+}
+
+func TestValidateValidMessage(t *testing.T) {
+	report := newTestRequest("000123").Validate()
+	assert.True(t, report.Valid())
+	assert.NoError(t, report.Err())
+	assert.Equal(t, ResponseCodeApproved, report.ResponseCode())
+}
+
+func TestValidationReportResponseCode(t *testing.T) {
+	tests := []struct {
+		name string
+		mti  MTIType
+		bits []int
+		want string
+	}{
+		{"approved", "0200", nil, ResponseCodeApproved},
+		{"card number", "0200", []int{2, 4}, ResponseCodeInvalidCardNumber},
+		{"amount", "0200", []int{4, 11}, ResponseCodeInvalidAmount},
+		{"format", "0200", []int{11}, ResponseCodeFormatError},
+		{"1993 approved", "1200", nil, ActionCodeApproved},
+		{"1993 card number", "1200", []int{2}, ActionCodeInvalidCardNumber},
+		{"1993 amount", "1200", []int{4}, ActionCodeInvalidAmount},
+		{"2003 format", "2200", []int{11}, ActionCodeFormatError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := &ValidationReport{MTI: tt.mti.ToMtiByte()}
+			for _, bit := range tt.bits {
+				report.Violations = append(report.Violations, &RuleViolation{Bit: bit, Rule: RuleLength})
+			}
+			assert.Equal(t, tt.want, report.ResponseCode())
+		})
+	}
+}
+
+func TestValidateMandatoryBitsByMTI(t *testing.T) {
+	nmm := NewMessage(DefaultPackager())
+	nmm.SetMtiString("0810")
+	nmm.SetString(7, "1018120000")
+	assert.EqualError(t, nmm.ValidateMandatoryBits(),
+		"bit 11 mandatory: missing\nbit 70 mandatory: missing\nbit 39 mandatory: missing for response")
+
+	reversal := newTestRequest("000123")
+	reversal.SetMtiString("0400")
+	assert.EqualError(t, reversal.ValidateMandatoryBits(), "bit 90 mandatory: missing for reversal")
+}
@@ -5,11 +5,12 @@ import (
 	"fmt"
 )
 
-func (m *Message) parseBitLength(b []byte, bitNum, cursor int) (length, prefixLen int, err error) {
+// parseBitLength returns the data length of the bit and the size in bytes of its length prefix
+func (m *Message) parseBitLength(b []byte, bitNum, cursor int) (length, prefixSize int, err error) {
 
-	prefixLen = m.packager.PrefixLengths[bitNum]
+	prefixLen := m.packager.PrefixLengths[bitNum]
 	if prefixLen == 0 {
-		return -1, prefixLen, fmt.Errorf("packager not found for bit %d", bitNum)
+		return -1, 0, fmt.Errorf("packager not found for bit %d", bitNum)
 	}
 
 	if prefixLen == FixedLength {
@@ -17,20 +18,82 @@ func (m *Message) parseBitLength(b []byte, bitNum, cursor int) (length, prefixLe
 		return length, 0, nil
 	}
 
-	if len(b[cursor:]) < prefixLen {
-		msg := fmt.Errorf("insufficient data for bit %d length: need %d, have %d", bitNum, prefixLen, len(b[cursor:]))
-		return length, prefixLen, errors.Join(msg, ErrFailedToParseBitmapData)
+	prefixSize = m.packager.PrefixSizes[bitNum]
+	if len(b[cursor:]) < prefixSize {
+		msg := fmt.Errorf("insufficient data for bit %d length: need %d, have %d", bitNum, prefixSize, len(b[cursor:]))
+		return length, prefixSize, errors.Join(msg, ErrFailedToParseBitmapData)
 	}
-	length, err = asciiBytesToInt(b[cursor : cursor+prefixLen])
+	length, err = decodeLen(b[cursor:cursor+prefixSize], m.packager.LengthEncodings[bitNum])
 	if err != nil {
 		msg := fmt.Errorf("failed to parse length for bit %d", bitNum)
-		return length, prefixLen, errors.Join(msg, ErrFailedToParseBitmapData)
+		return length, prefixSize, errors.Join(msg, err, ErrFailedToParseBitmapData)
 	}
 
 	return
 }
-func encodeLenInto(n, prefixLen int, dst []byte) {
-	copy(dst, fourDigitTable[n][4-prefixLen:])
+
+// encodeLenInto writes n as a length prefix of prefixLen digits into dst
+// using the given encoding. dst must be exactly the prefix size.
+// It fails when n does not fit the prefix instead of wrapping it.
+func encodeLenInto(n, prefixLen int, encoding Encoding, dst []byte) error {
+	length := BitLength{Encoding: encoding, Type: prefixLengthType(prefixLen)}
+	if n < 0 || n > length.MaxPrefixValue() {
+		return fmt.Errorf("length %d does not fit a %s %s prefix", n, encoding, length.Type)
+	}
+
+	switch encoding {
+	case EncodingBCD:
+		// right align the digits, the first nibble is zero for odd digit counts
+		digits := fourDigitTable[n][4-len(dst)*2:]
+		for i := range dst {
+			dst[i] = (digits[i*2]-'0')<<4 | (digits[i*2+1] - '0')
+		}
+	case EncodingBinary:
+		for i := len(dst) - 1; i >= 0; i-- {
+			dst[i] = byte(n)
+			n >>= 8
+		}
+	case EncodingEBCDIC, EncodingEBCDIC1047:
+		for i, c := range fourDigitTable[n][4-prefixLen:] {
+			// EBCDIC digits are 0xF0 - 0xF9
+			dst[i] = c | 0xF0
+		}
+	default:
+		copy(dst, fourDigitTable[n][4-prefixLen:])
+	}
+	return nil
+}
+
+// prefixLengthType returns the length type of a prefix of prefixLen digits
+func prefixLengthType(prefixLen int) LengthType {
+	switch prefixLen {
+	case LLVarLength:
+		return LengthTypeLLVar
+	case LLLVarLength:
+		return LengthTypeLLLVar
+	case LLLLVarLength:
+		return LengthTypeLLLLVar
+	default:
+		return LengthTypeFixed
+	}
+}
+
+// decodeLen parses a length prefix encoded with the given encoding
+func decodeLen(b []byte, encoding Encoding) (int, error) {
+	switch encoding {
+	case EncodingBCD:
+		return bcdBytesToInt(b)
+	case EncodingBinary:
+		n := 0
+		for _, c := range b {
+			n = n<<8 | int(c)
+		}
+		return n, nil
+	case EncodingEBCDIC, EncodingEBCDIC1047:
+		return ebcdicBytesToInt(b)
+	default:
+		return asciiBytesToInt(b)
+	}
 }
 
 var digitLookup = [256]int{
@@ -54,7 +117,32 @@ func asciiBytesToInt(b []byte) (int, error) {
 	return n, nil
 }
 
-// getTotalBitLength returns the total length of the bit
+// bcdBytesToInt converts a packed BCD byte array to an integer
+func bcdBytesToInt(b []byte) (int, error) {
+	n := 0
+	for _, c := range b {
+		hi, lo := int(c>>4), int(c&0x0F)
+		if hi > 9 || lo > 9 {
+			return 0, fmt.Errorf("invalid bcd byte %#x", c)
+		}
+		n = n*100 + hi*10 + lo
+	}
+	return n, nil
+}
+
+// ebcdicBytesToInt converts an EBCDIC digit byte array to an integer
+func ebcdicBytesToInt(b []byte) (int, error) {
+	n := 0
+	for _, c := range b {
+		if c < 0xF0 || c > 0xF9 {
+			return 0, fmt.Errorf("invalid digit %#x", c)
+		}
+		n = n*10 + int(c-0xF0)
+	}
+	return n, nil
+}
+
+// getTotalBitLength returns the total length of the bit on the wire
 // for LLVar, LLLVar, LLLLVar it returns the length of the data + the length of the length data
 func (m *Message) getTotalBitLength(bitNum int) (length int, err error) {
 
@@ -69,7 +157,7 @@ func (m *Message) getTotalBitLength(bitNum int) (length int, err error) {
 				len(m.isoMessageMap[bitNum]),
 			)
 		}
-		return maxLength, nil
+		return m.packager.valueSize(bitNum, maxLength), nil
 	}
 
 	length = len(m.isoMessageMap[bitNum])
@@ -82,5 +170,5 @@ func (m *Message) getTotalBitLength(bitNum int) (length int, err error) {
 		)
 	}
 
-	return length + prefixLen, nil
+	return m.packager.valueSize(bitNum, length) + m.packager.PrefixSizes[bitNum], nil
 }
@@ -0,0 +1,78 @@
+package iso8583
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLengthPrefixRoundTrip(t *testing.T) {
+	tests := []struct {
+		encoding string
+		length   string
+		value    string
+	}{
+		{"ascii", "LLVAR", "4111111111111111"},
+		{"ascii", "LLLVAR", strings.Repeat("X", 120)},
+		{"ascii", "LLLLVAR", strings.Repeat("X", 1200)},
+		{"bcd", "LLVAR", "4111111111111111"},
+		{"bcd", "LLLVAR", strings.Repeat("X", 120)},
+		{"binary", "LLVAR", strings.Repeat("X", 255)},
+		{"binary", "LLLVAR", strings.Repeat("X", 999)},
+		{"ebcdic", "LLVAR", "4111111111111111"},
+		{"ebcdic1047", "LLLVAR", strings.Repeat("X", 120)},
+		{"ascii", "LLVAR", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.encoding+" "+tt.length, func(t *testing.T) {
+			max := map[string]string{"LLVAR": "99", "LLLVAR": "999", "LLLLVAR": "9999"}[tt.length]
+			if tt.encoding == "binary" && tt.length == "LLVAR" {
+				max = "255"
+			}
+			packager := newTestPackager(t, "",
+				`"48": {"type": "ans", "length": {"type": "`+tt.length+`", "max": `+max+`, "encoding": "`+tt.encoding+`"}}`,
+				`"49": {"type": "n", "length": {"type": "FIXED", "max": 3}}`)
+
+			msg := NewMessage(packager)
+			msg.SetMtiString("0200")
+			msg.SetString(48, tt.value)
+			msg.SetString(49, "840")
+			b, err := msg.PackISO()
+			require.NoError(t, err)
+
+			got := NewMessage(packager)
+			require.NoError(t, got.Unpack(b))
+			assert.Equal(t, tt.value, got.GetString(48))
+			assert.Equal(t, "840", got.GetString(49))
+		})
+	}
+}
+
+func TestUnpackMalformedLength(t *testing.T) {
+	packager := newTestPackager(t, "", `"48": {"type": "ans", "length": {"type": "LLLVAR", "max": 999}}`)
+	bitmap := "0000000000010000" // bit 48
+
+	tests := map[string]string{
+		"not digits":       "0200" + bitmap + "0A1",
+		"short prefix":     "0200" + bitmap + "00",
+		"length over data": "0200" + bitmap + "010ABC",
+		"length over max":  "0200" + bitmap + "999" + strings.Repeat("A", 10),
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			msg := NewMessage(packager)
+			assert.Error(t, msg.Unpack([]byte(data)))
+		})
+	}
+}
+
+func TestEncodeLenIntoRejectsOverflow(t *testing.T) {
+	dst := make([]byte, 2)
+	assert.Error(t, encodeLenInto(100, LLVarLength, EncodingASCII, dst))
+	assert.Error(t, encodeLenInto(256, LLVarLength, EncodingBinary, dst[:1]))
+	assert.Error(t, encodeLenInto(-1, LLVarLength, EncodingASCII, dst))
+	require.NoError(t, encodeLenInto(99, LLVarLength, EncodingASCII, dst))
+	assert.Equal(t, "99", string(dst))
+}
@@ -0,0 +1,308 @@
+package iso8583
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultTimeLayout is the layout of time.Time fields without a layout option, DE 7 MMDDhhmmss
+const defaultTimeLayout = "0102150405"
+
+var (
+	ErrUnsupportedFieldType = errors.New("unsupported field type")
+	ErrInvalidFieldTag      = errors.New("invalid iso8583 struct tag")
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// fieldTag is a parsed iso8583 struct tag
+type fieldTag struct {
+	bit      int
+	subfield string
+	mti      bool
+	layout   string
+}
+
+// Marshal creates a message from the iso8583 struct tags of v, a struct or a pointer to a struct.
+//
+//	type Purchase struct {
+//	    MTI          string    `iso8583:"mti"`
+//	    PAN          string    `iso8583:"2"`
+//	    Amount       int64     `iso8583:"4"`
+//	    Transmission time.Time `iso8583:"7,layout=0102150405"`
+//	    OriginalStan string    `iso8583:"90.originalStan"`
+//	    Processing   struct {
+//	        TransactionType string `iso8583:"transactionType"`
+//	    } `iso8583:"3"`
+//	}
+//
+// Supported types are string, []byte, integers, time.Time (layout option, MMDDhhmmss by default)
+// and for a bit with subfields a struct tagged with the subfield names.
+// Integers of fixed length fields are zero padded. Zero values are skipped,
+// use a pointer to set a zero value.
+func Marshal(v any, packager *IsoPackager) (*Message, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %T is not a struct", ErrUnsupportedFieldType, v)
+	}
+
+	m := NewMessage(packager)
+	if err := m.marshalStruct(rv, 0); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Unmarshal sets the fields of v, a pointer to a struct, from the message using the
+// iso8583 struct tags described in Marshal. Fields of bits that are not set are left unchanged.
+func Unmarshal(m *Message, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: %T is not a pointer to a struct", ErrUnsupportedFieldType, v)
+	}
+	return m.unmarshalStruct(rv.Elem(), 0)
+}
+
+func (m *Message) marshalStruct(rv reflect.Value, parentBit int) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag, ok := sf.Tag.Lookup("iso8583")
+		if !ok && sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			if err := m.marshalStruct(rv.Field(i), parentBit); err != nil {
+				return err
+			}
+			continue
+		}
+		if !ok || tag == "-" || !sf.IsExported() {
+			continue
+		}
+
+		t, err := parseFieldTag(tag, parentBit)
+		if err == nil {
+			err = m.marshalField(rv.Field(i), t)
+		}
+		if err != nil {
+			return fieldError(sf.Name, t, err)
+		}
+	}
+	return nil
+}
+
+func (m *Message) marshalField(fv reflect.Value, t fieldTag) error {
+	set := false
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			return nil
+		}
+		fv, set = fv.Elem(), true
+	}
+
+	if t.mti {
+		if fv.Kind() != reflect.String {
+			return fmt.Errorf("%w: mti must be a string", ErrUnsupportedFieldType)
+		}
+		mti := MTIType(fv.String())
+		if len(mti) != len(MTITypeByte{}) || !mti.ToMtiByte().IsValid() {
+			return fmt.Errorf("%w: mti %q", ErrInvalidValue, mti)
+		}
+		m.SetMtiString(mti)
+		return nil
+	}
+
+	if fv.Kind() == reflect.Struct && fv.Type() != timeType {
+		if t.subfield != "" {
+			return fmt.Errorf("%w: subfield %s cannot be a struct", ErrUnsupportedFieldType, t.subfield)
+		}
+		return m.marshalStruct(fv, t.bit)
+	}
+
+	if !set && fv.IsZero() {
+		return nil
+	}
+
+	var value string
+	switch {
+	case fv.Type() == timeType:
+		value = fv.Interface().(time.Time).Format(t.layout)
+	case fv.Kind() == reflect.String:
+		value = fv.String()
+	case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.Uint8:
+		if t.subfield == "" {
+			m.SetByte(t.bit, fv.Bytes())
+			return nil
+		}
+		value = string(fv.Bytes())
+	case fv.CanInt():
+		if fv.Int() < 0 {
+			return fmt.Errorf("%w: negative value %d", ErrInvalidValue, fv.Int())
+		}
+		value = m.padInt(t, strconv.FormatInt(fv.Int(), 10))
+	case fv.CanUint():
+		value = m.padInt(t, strconv.FormatUint(fv.Uint(), 10))
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedFieldType, fv.Type())
+	}
+
+	if t.subfield != "" {
+		return m.SetSubfield(t.bit, t.subfield, value)
+	}
+	m.SetString(t.bit, value)
+	return nil
+}
+
+// padInt zero pads an integer to the length of a fixed field,
+// subfields are padded by SetSubfield
+func (m *Message) padInt(t fieldTag, value string) string {
+	if t.subfield != "" || m.packager.PrefixLengths[t.bit] != FixedLength {
+		return value
+	}
+	if pad := m.packager.MaxLengths[t.bit] - len(value); pad > 0 {
+		return strings.Repeat("0", pad) + value
+	}
+	return value
+}
+
+func (m *Message) unmarshalStruct(rv reflect.Value, parentBit int) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag, ok := sf.Tag.Lookup("iso8583")
+		if !ok && sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			if err := m.unmarshalStruct(rv.Field(i), parentBit); err != nil {
+				return err
+			}
+			continue
+		}
+		if !ok || tag == "-" || !sf.IsExported() {
+			continue
+		}
+
+		t, err := parseFieldTag(tag, parentBit)
+		if err == nil {
+			err = m.unmarshalField(rv.Field(i), t)
+		}
+		if err != nil {
+			return fieldError(sf.Name, t, err)
+		}
+	}
+	return nil
+}
+
+func (m *Message) unmarshalField(fv reflect.Value, t fieldTag) error {
+	var value string
+	switch {
+	case t.mti:
+		if m.MTI == EmptyMti {
+			return nil
+		}
+		value = string(m.MTI[:])
+	case !m.HasBit(t.bit):
+		return nil
+	case t.subfield != "":
+		var err error
+		if value, err = m.GetSubfield(t.bit, t.subfield); err != nil {
+			return err
+		}
+	default:
+		value = m.GetString(t.bit)
+	}
+
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		fv = fv.Elem()
+	}
+
+	switch {
+	case t.mti && fv.Kind() != reflect.String:
+		return fmt.Errorf("%w: mti must be a string", ErrUnsupportedFieldType)
+	case fv.Type() == timeType:
+		tm, err := time.Parse(t.layout, value)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidValue, err)
+		}
+		fv.Set(reflect.ValueOf(tm))
+	case fv.Kind() == reflect.Struct:
+		if t.subfield != "" {
+			return fmt.Errorf("%w: subfield %s cannot be a struct", ErrUnsupportedFieldType, t.subfield)
+		}
+		return m.unmarshalStruct(fv, t.bit)
+	case fv.Kind() == reflect.String:
+		fv.SetString(strings.Clone(value))
+	case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.Uint8:
+		fv.SetBytes(bytes.Clone([]byte(value)))
+	case fv.CanInt():
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, fv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidValue, err)
+		}
+		fv.SetInt(n)
+	case fv.CanUint():
+		n, err := strconv.ParseUint(strings.TrimSpace(value), 10, fv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidValue, err)
+		}
+		fv.SetUint(n)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedFieldType, fv.Type())
+	}
+	return nil
+}
+
+// parseFieldTag parses "mti", "<bit>", "<bit>.<subfield>" or inside a subfields
+// struct "<subfield>", followed by options e.g. ",layout=0102150405"
+func parseFieldTag(tag string, parentBit int) (fieldTag, error) {
+	name, options, _ := strings.Cut(tag, ",")
+	t := fieldTag{layout: defaultTimeLayout}
+
+	for _, option := range strings.Split(options, ",") {
+		if layout, ok := strings.CutPrefix(option, "layout="); ok {
+			t.layout = layout
+		} else if option != "" {
+			return t, fmt.Errorf("%w: option %q", ErrInvalidFieldTag, option)
+		}
+	}
+
+	if parentBit != 0 {
+		if name == "" || strings.Contains(name, ".") {
+			return t, fmt.Errorf("%w: subfield %q", ErrInvalidFieldTag, name)
+		}
+		t.bit, t.subfield = parentBit, name
+		return t, nil
+	}
+
+	if name == "mti" {
+		t.mti = true
+		return t, nil
+	}
+
+	bit, subfield, _ := strings.Cut(name, ".")
+	n, err := strconv.Atoi(bit)
+	if err != nil || n < 2 || n > MaxBitNumber {
+		return t, fmt.Errorf("%w: bit %q", ErrInvalidFieldTag, bit)
+	}
+	t.bit, t.subfield = n, subfield
+	return t, nil
+}
+
+// fieldError names the struct field and the parsed bit and subfield of the error,
+// only the field when its tag could not be parsed
+func fieldError(field string, t fieldTag, err error) error {
+	switch {
+	case t.mti:
+		return fmt.Errorf("field %s mti: %w", field, err)
+	case t.bit == 0:
+		return fmt.Errorf("field %s: %w", field, err)
+	case t.subfield != "":
+		return fmt.Errorf("field %s bit %d.%s: %w", field, t.bit, t.subfield, err)
+	default:
+		return fmt.Errorf("field %s bit %d: %w", field, t.bit, err)
+	}
+}
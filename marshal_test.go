@@ -0,0 +1,108 @@
+package iso8583
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testPurchase struct {
+	MTI          string    `iso8583:"mti"`
+	PAN          string    `iso8583:"2"`
+	Amount       int64     `iso8583:"4"`
+	Transmission time.Time `iso8583:"7,layout=0102150405"`
+	Stan         *uint32   `iso8583:"11"`
+	OriginalStan string    `iso8583:"90.originalStan"`
+	Processing   struct {
+		TransactionType string `iso8583:"transactionType"`
+		FromAccount     string `iso8583:"fromAccount"`
+		ToAccount       string `iso8583:"toAccount"`
+	} `iso8583:"3"`
+	Ignored string `iso8583:"-"`
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	stan := uint32(0)
+	in := testPurchase{
+		MTI:          "0200",
+		PAN:          "4111111111111111",
+		Amount:       1250,
+		Transmission: time.Date(0, 12, 31, 23, 59, 58, 0, time.UTC),
+		Stan:         &stan,
+		OriginalStan: "123456",
+		Ignored:      "skipped",
+	}
+	in.Processing.TransactionType = "00"
+	in.Processing.FromAccount = "10"
+	in.Processing.ToAccount = "20"
+
+	msg, err := Marshal(&in, DefaultPackager())
+	require.NoError(t, err)
+	assert.Equal(t, "000000001250", msg.GetString(4))
+	assert.Equal(t, "1231235958", msg.GetString(7))
+	assert.Equal(t, "000000", msg.GetString(11), "a pointer sets the zero value")
+	assert.Equal(t, "001020", msg.GetString(3))
+
+	var out testPurchase
+	require.NoError(t, Unmarshal(msg, &out))
+	in.Ignored = ""
+	assert.Equal(t, in, out)
+}
+
+func TestMarshalErrorNamesParsedBit(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+		want string
+	}{
+		{
+			"bit with options",
+			&struct {
+				Amount int64 `iso8583:"4,layout=060102"`
+			}{Amount: -1},
+			"field Amount bit 4: value does not match bit type: negative value -1",
+		},
+		{
+			"subfield",
+			&struct {
+				Stan []int `iso8583:"90.originalStan"`
+			}{Stan: []int{1}},
+			"field Stan bit 90.originalStan: unsupported field type: []int",
+		},
+		{
+			"mti",
+			&struct {
+				MTI string `iso8583:"mti"`
+			}{MTI: "02"},
+			`field MTI mti: value does not match bit type: mti "02"`,
+		},
+		{
+			"invalid tag",
+			&struct {
+				PAN string `iso8583:"1"`
+			}{PAN: "4111"},
+			`field PAN: invalid iso8583 struct tag: bit "1"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Marshal(tt.v, DefaultPackager())
+			require.Error(t, err)
+			assert.Equal(t, tt.want, err.Error())
+		})
+	}
+}
+
+func TestUnmarshalInvalidValue(t *testing.T) {
+	msg := NewMessage(DefaultPackager())
+	msg.SetString(4, "12A")
+
+	var out struct {
+		Amount int64 `iso8583:"4"`
+	}
+	err := Unmarshal(msg, &out)
+	assert.ErrorIs(t, err, ErrInvalidValue)
+	assert.Contains(t, err.Error(), "field Amount bit 4: ")
+}
@@ -16,6 +16,40 @@ const (
 	MTINMMResponse             MTIType = "0810"
 )
 
+// ISO 8583:1993 MTIs
+const (
+	MTI1993AuthorizationRequest        MTIType = "1100"
+	MTI1993AuthorizationResponse       MTIType = "1110"
+	MTI1993AuthorizationAdvice         MTIType = "1120"
+	MTI1993AuthorizationAdviceResponse MTIType = "1130"
+	MTI1993FinancialRequest            MTIType = "1200"
+	MTI1993FinancialResponse           MTIType = "1210"
+	MTI1993FinancialAdvice             MTIType = "1220"
+	MTI1993FinancialAdviceResponse     MTIType = "1230"
+	MTI1993ReversalAdvice              MTIType = "1420"
+	MTI1993RepeatedReversalAdvice      MTIType = "1421"
+	MTI1993ReversalAdviceResponse      MTIType = "1430"
+	MTI1993NMMRequest                  MTIType = "1804"
+	MTI1993NMMResponse                 MTIType = "1814"
+)
+
+// ISO 8583:2003 MTIs
+const (
+	MTI2003AuthorizationRequest        MTIType = "2100"
+	MTI2003AuthorizationResponse       MTIType = "2110"
+	MTI2003AuthorizationAdvice         MTIType = "2120"
+	MTI2003AuthorizationAdviceResponse MTIType = "2130"
+	MTI2003FinancialRequest            MTIType = "2200"
+	MTI2003FinancialResponse           MTIType = "2210"
+	MTI2003FinancialAdvice             MTIType = "2220"
+	MTI2003FinancialAdviceResponse     MTIType = "2230"
+	MTI2003ReversalAdvice              MTIType = "2420"
+	MTI2003RepeatedReversalAdvice      MTIType = "2421"
+	MTI2003ReversalAdviceResponse      MTIType = "2430"
+	MTI2003NMMRequest                  MTIType = "2804"
+	MTI2003NMMResponse                 MTIType = "2814"
+)
+
 type MTITypeByte [4]byte
 
 var (
@@ -30,18 +64,56 @@ var (
 	MTINMMResponseByte             MTITypeByte = [4]byte{0x30, 0x38, 0x31, 0x30}
 )
 
-// Pre-computed valid MTI lookup for O(1) validation
-var validMTIs = map[[4]byte]struct{}{
-	MTICardProcessingRequestByte:   {},
-	MTICardProcessingResponseByte:  {},
-	MTIFinancialRequestByte:        {},
-	MTIFinancialResponseByte:       {},
-	MTIReversalRequestByte:         {},
-	MTIReversalResponseByte:        {},
-	MTIRepeatedReversalRequestByte: {},
-	MTINMMRequestByte:              {},
-	MTINMMResponseByte:             {},
-}
+// MTIVersion is the first MTI digit, the version of ISO 8583
+type MTIVersion byte
+
+const (
+	MTIVersion1987     MTIVersion = '0'
+	MTIVersion1993     MTIVersion = '1'
+	MTIVersion2003     MTIVersion = '2'
+	MTIVersionNational MTIVersion = '8'
+	MTIVersionPrivate  MTIVersion = '9'
+)
+
+// MTIClass is the second MTI digit, the overall purpose of the message
+type MTIClass byte
+
+const (
+	MTIClassAuthorization     MTIClass = '1'
+	MTIClassFinancial         MTIClass = '2'
+	MTIClassFileAction        MTIClass = '3'
+	MTIClassReversal          MTIClass = '4'
+	MTIClassReconciliation    MTIClass = '5'
+	MTIClassAdministrative    MTIClass = '6'
+	MTIClassFeeCollection     MTIClass = '7'
+	MTIClassNetworkManagement MTIClass = '8'
+)
+
+// MTIFunction is the third MTI digit, the function of the message in the flow
+type MTIFunction byte
+
+const (
+	MTIFunctionRequest                 MTIFunction = '0'
+	MTIFunctionRequestResponse         MTIFunction = '1'
+	MTIFunctionAdvice                  MTIFunction = '2'
+	MTIFunctionAdviceResponse          MTIFunction = '3'
+	MTIFunctionNotification            MTIFunction = '4'
+	MTIFunctionNotificationAcknowledge MTIFunction = '5'
+	MTIFunctionInstruction             MTIFunction = '6'
+	MTIFunctionInstructionAcknowledge  MTIFunction = '7'
+)
+
+// MTIOrigin is the fourth MTI digit, the originator of the message
+type MTIOrigin byte
+
+const (
+	MTIOriginAcquirer       MTIOrigin = '0'
+	MTIOriginAcquirerRepeat MTIOrigin = '1'
+	MTIOriginIssuer         MTIOrigin = '2'
+	MTIOriginIssuerRepeat   MTIOrigin = '3'
+	MTIOriginOther          MTIOrigin = '4'
+	MTIOriginOtherRepeat    MTIOrigin = '5'
+)
 
 func (m MTITypeByte) ToMtiString() MTIType {
 	return MTIType(m[:])
@@ -60,7 +132,109 @@ func (ms MTIType) ToMtiByte() MTITypeByte {
 	return b
 }
 
-func isValidMti(mti MTITypeByte) bool {
-	_, ok := validMTIs[mti]
-	return ok
+// MTI builds the MTI of the version from its class, function and origin
+func (v MTIVersion) MTI(class MTIClass, function MTIFunction, origin MTIOrigin) MTITypeByte {
+	return MTITypeByte{byte(v), byte(class), byte(function), byte(origin)}
+}
+
+// is1993 reports whether the version uses the ISO 8583:1993 field layout, 2003 included
+func (v MTIVersion) is1993() bool {
+	return v == MTIVersion1993 || v == MTIVersion2003
+}
+
+// NetworkManagementCodeBit returns the bit of the network management code,
+// DE 70 for 1987 and the DE 24 function code for 1993 and 2003
+func (v MTIVersion) NetworkManagementCodeBit() int {
+	if v.is1993() {
+		return 24
+	}
+	return 70
+}
+
+// OriginalDataElementsBit returns the bit of the original data elements of a reversal,
+// DE 90 for 1987 and DE 56 for 1993 and 2003
+func (v MTIVersion) OriginalDataElementsBit() int {
+	if v.is1993() {
+		return 56
+	}
+	return 90
+}
+
+// NMMRequest returns the network management request MTI of the version, 0800 or x804
+func (v MTIVersion) NMMRequest() MTITypeByte {
+	if v.is1993() {
+		return v.MTI(MTIClassNetworkManagement, MTIFunctionRequest, MTIOriginOther)
+	}
+	return v.MTI(MTIClassNetworkManagement, MTIFunctionRequest, MTIOriginAcquirer)
+}
+
+// Reversal returns the reversal MTI of the version, 0400 request or x420 advice
+func (v MTIVersion) Reversal() MTITypeByte {
+	if v.is1993() {
+		return v.MTI(MTIClassReversal, MTIFunctionAdvice, MTIOriginAcquirer)
+	}
+	return v.MTI(MTIClassReversal, MTIFunctionRequest, MTIOriginAcquirer)
+}
+
+func (m MTITypeByte) Version() MTIVersion {
+	return MTIVersion(m[0])
+}
+
+func (m MTITypeByte) Class() MTIClass {
+	return MTIClass(m[1])
+}
+
+func (m MTITypeByte) Function() MTIFunction {
+	return MTIFunction(m[2])
+}
+
+func (m MTITypeByte) Origin() MTIOrigin {
+	return MTIOrigin(m[3])
+}
+
+// IsValid checks every MTI digit is a known version, class, function and origin
+func (m MTITypeByte) IsValid() bool {
+	switch m.Version() {
+	case MTIVersion1987, MTIVersion1993, MTIVersion2003, MTIVersionNational, MTIVersionPrivate:
+	default:
+		return false
+	}
+	if m.Class() < MTIClassAuthorization || m.Class() > MTIClassNetworkManagement {
+		return false
+	}
+	if m.Function() < MTIFunctionRequest || m.Function() > MTIFunctionInstructionAcknowledge {
+		return false
+	}
+	return m.Origin() >= MTIOriginAcquirer && m.Origin() <= MTIOriginOtherRepeat
+}
+
+// IsRequest reports whether the MTI expects a response: requests, advices,
+// notifications and instructions (even function digit)
+func (m MTITypeByte) IsRequest() bool {
+	return m.IsValid() && (m.Function()-MTIFunctionRequest)%2 == 0
+}
+
+// IsResponse reports whether the MTI answers a request (odd function digit)
+func (m MTITypeByte) IsResponse() bool {
+	return m.IsValid() && (m.Function()-MTIFunctionRequest)%2 == 1
+}
+
+// IsRepeat reports whether the MTI is a repeat (odd origin digit)
+func (m MTITypeByte) IsRepeat() bool {
+	return m.IsValid() && (m.Origin()-MTIOriginAcquirer)%2 == 1
+}
+
+// Response returns the response MTI of a request, advice, notification or instruction.
+// The version and class are kept, the function is moved to its response and
+// repeats are answered with the non-repeat origin, e.g. 0401 -> 0410, 1420 -> 1430.
+func (m MTITypeByte) Response() (MTITypeByte, error) {
+	if !m.IsRequest() {
+		return m, ErrNotRequestMti
+	}
+	res := m
+	res[2]++
+	if m.IsRepeat() {
+		res[3]--
+	}
+	return res, nil
 }
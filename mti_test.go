@@ -0,0 +1,107 @@
+package iso8583
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMTIParts(t *testing.T) {
+	mti := MTIType("1421").ToMtiByte()
+	assert.Equal(t, MTIVersion1993, mti.Version())
+	assert.Equal(t, MTIClassReversal, mti.Class())
+	assert.Equal(t, MTIFunctionAdvice, mti.Function())
+	assert.Equal(t, MTIOriginAcquirerRepeat, mti.Origin())
+	assert.Equal(t, mti, MTIVersion1993.MTI(MTIClassReversal, MTIFunctionAdvice, MTIOriginAcquirerRepeat))
+	assert.Equal(t, "1421", mti.String())
+}
+
+func TestMTIKind(t *testing.T) {
+	tests := []struct {
+		mti      MTIType
+		valid    bool
+		request  bool
+		response bool
+		repeat   bool
+	}{
+		{"0200", true, true, false, false},
+		{"0210", true, false, true, false},
+		{"0401", true, true, false, true},
+		{"1804", true, true, false, false},
+		{"2430", true, false, true, false},
+		{"9100", true, true, false, false},
+		{"3200", false, false, false, false},
+		{"0900", false, false, false, false},
+		{"0280", false, false, false, false},
+		{"0206", false, false, false, false},
+		{"02A0", false, false, false, false},
+	}
+	for _, tt := range tests {
+		mti := tt.mti.ToMtiByte()
+		assert.Equal(t, tt.valid, mti.IsValid(), "%s valid", tt.mti)
+		assert.Equal(t, tt.request, mti.IsRequest(), "%s request", tt.mti)
+		assert.Equal(t, tt.response, mti.IsResponse(), "%s response", tt.mti)
+		assert.Equal(t, tt.repeat, mti.IsRepeat(), "%s repeat", tt.mti)
+	}
+}
+
+func TestMTIResponse(t *testing.T) {
+	tests := []struct {
+		mti  MTIType
+		want MTIType
+	}{
+		{"0100", "0110"},
+		{"0200", "0210"},
+		{"0220", "0230"},
+		{"0401", "0410"},
+		{"0800", "0810"},
+		{"1420", "1430"},
+		{"1421", "1430"},
+		{"1804", "1814"},
+		{"2203", "2212"},
+	}
+	for _, tt := range tests {
+		res, err := tt.mti.ToMtiByte().Response()
+		require.NoError(t, err, tt.mti)
+		assert.Equal(t, tt.want.ToMtiByte(), res, tt.mti)
+	}
+
+	for _, mti := range []MTIType{"0210", "1430", "0000", "3200"} {
+		_, err := mti.ToMtiByte().Response()
+		assert.ErrorIs(t, err, ErrNotRequestMti, mti)
+	}
+}
+
+func TestMTIVersionBits(t *testing.T) {
+	tests := []struct {
+		version  MTIVersion
+		nmmBit   int
+		origBit  int
+		nmm      MTIType
+		reversal MTIType
+	}{
+		{MTIVersion1987, 70, 90, "0800", "0400"},
+		{MTIVersion1993, 24, 56, "1804", "1420"},
+		{MTIVersion2003, 24, 56, "2804", "2420"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.nmmBit, tt.version.NetworkManagementCodeBit())
+		assert.Equal(t, tt.origBit, tt.version.OriginalDataElementsBit())
+		assert.Equal(t, tt.nmm.ToMtiByte(), tt.version.NMMRequest())
+		assert.Equal(t, tt.reversal.ToMtiByte(), tt.version.Reversal())
+	}
+}
+
+func TestCreateResponseISO(t *testing.T) {
+	req := newTestRequest("000123")
+	res, err := CreateResponseISO(req, ResponseCodeApproved)
+	require.NoError(t, err)
+	assert.Equal(t, MTIType("0210").ToMtiByte(), res.MTI)
+	assert.Equal(t, ResponseCodeApproved, res.GetString(39))
+	assert.Equal(t, req.GetMessageKey(), res.GetMessageKey())
+	assert.False(t, req.HasBit(39), "the request is left unchanged")
+
+	_, err = CreateResponseISO(res, ResponseCodeApproved)
+	assert.ErrorIs(t, err, ErrNotRequestMti)
+}
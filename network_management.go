@@ -0,0 +1,332 @@
+package iso8583
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pentaly7/iso8583/framing"
+)
+
+// Network management information codes (DE 70)
+const (
+	NMMSignOn      = "001"
+	NMMSignOff     = "002"
+	NMMKeyExchange = "161"
+	NMMEchoTest    = "301"
+)
+
+// ISO 8583:1993 and 2003 network management function codes (DE 24)
+const (
+	FunctionCodeSignOn      = "801"
+	FunctionCodeSignOff     = "802"
+	FunctionCodeKeyExchange = "811"
+	FunctionCodeEchoTest    = "831"
+)
+
+// ActionCodeNetworkManagementAccepted is the ISO 8583:1993 and 2003 action code (DE 39)
+// of an accepted network management request
+const ActionCodeNetworkManagementAccepted = "800"
+
+// signOffTimeout bounds the sign-off sent when Run is cancelled
+const signOffTimeout = 2 * time.Second
+
+// minReconnectDelay and maxReconnectDelay bound the reconnect backoff,
+// a Dial that fails at once must not reconnect in a hot loop
+const (
+	minReconnectDelay = 100 * time.Millisecond
+	maxReconnectDelay = time.Minute
+)
+
+var (
+	ErrLinkDown           = errors.New("network link down")
+	ErrNMMRequestDeclined = errors.New("network management request declined")
+)
+
+// NetworkManager keeps a client connection signed on.
+// It sends a sign-on after connecting, periodic echo tests and key exchanges,
+// answers incoming echo tests and reconnects when the link goes down.
+type NetworkManager struct {
+	// Dial opens a new connection for every (re)connect
+	Dial func(ctx context.Context) (io.ReadWriteCloser, error)
+	// Version selects 0800 with DE 70 (1987) or x804 with the DE 24 function code (1993, 2003)
+	Version MTIVersion
+
+	EchoInterval        time.Duration // interval between echo tests, 0 disables echo tests
+	MaxMissedEchoes     int           // consecutive failed echo tests before the link is down
+	ResponseTimeout     time.Duration // time to wait for a network management response
+	ReconnectDelay      time.Duration // wait before reconnecting, doubled while reconnecting fails
+	KeyExchangeInterval time.Duration // interval between key exchanges, 0 disables key exchange
+
+	// NextStan returns the DE 11 of the next network management request,
+	// by default an internal counter is used
+	NextStan func() string
+	// PrepareRequest is called before sending each request, e.g. to add DE 53 or DE 48 for key exchange
+	PrepareRequest func(msg *Message)
+	// OnKeyExchange is called with the approved key exchange response
+	OnKeyExchange func(res *Message)
+	// OnUnmatched receives incoming messages other than echo tests and unmatched responses
+	OnUnmatched func(msg *Message)
+	// OnStatusChange is called when the link goes up or down
+	OnStatusChange func(up bool)
+	// OnError is called for errors that do not stop Run
+	OnError func(err error)
+
+	packager *IsoPackager
+	codec    *framing.Codec
+
+	mu     sync.RWMutex
+	client *Client
+	up     atomic.Bool
+	stan   atomic.Uint32
+}
+
+// NewNetworkManager creates a network manager with a 60s echo interval,
+// 3 missed echoes, 30s response timeout and 5s reconnect delay
+func NewNetworkManager(packager *IsoPackager, codec *framing.Codec, dial func(ctx context.Context) (io.ReadWriteCloser, error)) *NetworkManager {
+	return &NetworkManager{
+		Dial:            dial,
+		Version:         MTIVersion1987,
+		EchoInterval:    60 * time.Second,
+		MaxMissedEchoes: 3,
+		ResponseTimeout: 30 * time.Second,
+		ReconnectDelay:  5 * time.Second,
+		packager:        packager,
+		codec:           codec,
+	}
+}
+
+// Run connects, signs on and keeps the link alive until ctx is done.
+// The reconnect delay starts at ReconnectDelay (at least 100ms) and doubles up to a minute
+// while connecting or signing on fails.
+func (n *NetworkManager) Run(ctx context.Context) error {
+	var delay time.Duration
+	for {
+		err := n.runSession(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		n.reportError(err)
+
+		// the link was signed on, start the backoff again
+		if errors.Is(err, ErrLinkDown) {
+			delay = 0
+		}
+		delay = min(max(delay*2, n.ReconnectDelay, minReconnectDelay), max(n.ReconnectDelay, maxReconnectDelay))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// Client returns the signed on client, nil while the link is down
+func (n *NetworkManager) Client() *Client {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.client
+}
+
+// IsUp reports whether the link is signed on
+func (n *NetworkManager) IsUp() bool {
+	return n.up.Load()
+}
+
+// runSession handles a single connection until it goes down
+func (n *NetworkManager) runSession(ctx context.Context) error {
+	conn, err := n.Dial(ctx)
+	if err != nil {
+		return err
+	}
+
+	var client *Client
+	client = newClient(conn, n.packager, n.codec,
+		WithUnmatchedHandler(func(msg *Message) {
+			n.handleIncoming(client, msg)
+		}),
+		WithErrorHandler(n.OnError),
+	)
+	// the read loop starts once client is assigned
+	go client.readLoop()
+	defer client.Close()
+
+	if _, err = n.send(ctx, client, NMMSignOn); err != nil {
+		return fmt.Errorf("sign on: %w", err)
+	}
+
+	n.setClient(client)
+	defer n.setClient(nil)
+
+	var echo <-chan time.Time
+	if n.EchoInterval > 0 {
+		ticker := time.NewTicker(n.EchoInterval)
+		defer ticker.Stop()
+		echo = ticker.C
+	}
+
+	var keyExchange <-chan time.Time
+	if n.KeyExchangeInterval > 0 {
+		ticker := time.NewTicker(n.KeyExchangeInterval)
+		defer ticker.Stop()
+		keyExchange = ticker.C
+	}
+
+	missed := 0
+	for {
+		select {
+		case <-ctx.Done():
+			signOffCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), signOffTimeout)
+			if _, err = n.send(signOffCtx, client, NMMSignOff); err != nil {
+				n.reportError(fmt.Errorf("sign off: %w", err))
+			}
+			cancel()
+			return ctx.Err()
+		case <-client.Done():
+			return errors.Join(ErrLinkDown, client.Err())
+		case <-echo:
+			if _, err = n.send(ctx, client, NMMEchoTest); err != nil {
+				missed++
+				n.reportError(fmt.Errorf("echo test %d/%d: %w", missed, n.MaxMissedEchoes, err))
+				if missed >= n.MaxMissedEchoes {
+					return fmt.Errorf("%w: %d missed echo tests", ErrLinkDown, missed)
+				}
+				continue
+			}
+			missed = 0
+		case <-keyExchange:
+			res, err := n.send(ctx, client, NMMKeyExchange)
+			if err != nil {
+				n.reportError(fmt.Errorf("key exchange: %w", err))
+				continue
+			}
+			if n.OnKeyExchange != nil {
+				n.OnKeyExchange(res)
+			}
+		}
+	}
+}
+
+// send sends a network management request with the code and checks its response
+func (n *NetworkManager) send(ctx context.Context, client *Client, code string) (*Message, error) {
+	req := NewMessage(n.packager)
+	req.SetMTIByte(n.Version.NMMRequest())
+	req.SetString(7, time.Now().UTC().Format("0102150405"))
+	req.SetString(11, n.nextStan())
+	req.SetString(n.Version.NetworkManagementCodeBit(), networkManagementCode(n.Version, code))
+	if n.PrepareRequest != nil {
+		n.PrepareRequest(req)
+	}
+
+	if err := req.ValidateMandatoryBits(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, n.ResponseTimeout)
+	defer cancel()
+
+	res, err := client.Send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = res.ValidateMandatoryBits(); err != nil {
+		return nil, err
+	}
+	if rc := res.GetString(39); !nmmApproved(n.Version, rc) {
+		return nil, fmt.Errorf("%w: network management code %s response code %s", ErrNMMRequestDeclined, code, rc)
+	}
+
+	return res, nil
+}
+
+// handleIncoming answers echo tests and hands every other message to OnUnmatched
+func (n *NetworkManager) handleIncoming(client *Client, msg *Message) {
+	version := msg.MTI.Version()
+	if msg.IsNMM() && msg.IsRequest() &&
+		msg.GetString(version.NetworkManagementCodeBit()) == networkManagementCode(version, NMMEchoTest) {
+		report := msg.Validate()
+		if !report.Valid() {
+			n.reportError(report.Err())
+		}
+
+		res, err := CreateResponseISO(msg, nmmResponseCode(version, report))
+		if err == nil {
+			err = client.WriteMessage(res)
+		}
+		if err != nil {
+			n.reportError(fmt.Errorf("echo test response: %w", err))
+		}
+		return
+	}
+
+	if n.OnUnmatched != nil {
+		n.OnUnmatched(msg)
+	}
+}
+
+// nmmApproved reports whether the DE 39 of a network management response approves the request:
+// response code 00 for 1987, action code 800 or 000 for 1993 and 2003
+func nmmApproved(version MTIVersion, rc string) bool {
+	if version.is1993() {
+		return rc == ActionCodeNetworkManagementAccepted || rc == ActionCodeApproved
+	}
+	return rc == ResponseCodeApproved
+}
+
+// nmmResponseCode returns the DE 39 of the response to a network management request,
+// action code 800 when a 1993 or 2003 request is valid
+func nmmResponseCode(version MTIVersion, report *ValidationReport) string {
+	if report.Valid() && version.is1993() {
+		return ActionCodeNetworkManagementAccepted
+	}
+	return report.ResponseCode()
+}
+
+// networkManagementCode returns the DE 24 function code of a DE 70 code for 1993 and 2003
+func networkManagementCode(version MTIVersion, code string) string {
+	if !version.is1993() {
+		return code
+	}
+	switch code {
+	case NMMSignOn:
+		return FunctionCodeSignOn
+	case NMMSignOff:
+		return FunctionCodeSignOff
+	case NMMKeyExchange:
+		return FunctionCodeKeyExchange
+	case NMMEchoTest:
+		return FunctionCodeEchoTest
+	default:
+		return code
+	}
+}
+
+func (n *NetworkManager) nextStan() string {
+	if n.NextStan != nil {
+		return n.NextStan()
+	}
+	return fmt.Sprintf("%06d", n.stan.Add(1)%1000000)
+}
+
+func (n *NetworkManager) setClient(client *Client) {
+	n.mu.Lock()
+	n.client = client
+	n.mu.Unlock()
+
+	up := client != nil
+	if n.up.Swap(up) != up && n.OnStatusChange != nil {
+		n.OnStatusChange(up)
+	}
+}
+
+func (n *NetworkManager) reportError(err error) {
+	if n.OnError != nil {
+		n.OnError(err)
+	}
+}
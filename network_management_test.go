@@ -0,0 +1,247 @@
+package iso8583
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pentaly7/iso8583/framing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNMMApproved(t *testing.T) {
+	tests := []struct {
+		version MTIVersion
+		rc      string
+		want    bool
+	}{
+		{MTIVersion1987, "00", true},
+		{MTIVersion1987, "800", false},
+		{MTIVersion1987, "91", false},
+		{MTIVersion1993, "800", true},
+		{MTIVersion1993, "000", true},
+		{MTIVersion1993, "00", false},
+		{MTIVersion1993, "904", false},
+		{MTIVersion2003, "800", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, nmmApproved(tt.version, tt.rc), "version %c rc %s", tt.version, tt.rc)
+	}
+}
+
+func TestNetworkManagerSignOnAndEcho(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  MTIVersion
+		packager func() *IsoPackager
+		approved string
+		echo     MTIType
+	}{
+		{"1987", MTIVersion1987, DefaultPackager, ResponseCodeApproved, "0800"},
+		{"1993", MTIVersion1993, DefaultPackager1993, ActionCodeNetworkManagementAccepted, "1804"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			codec := framing.NewCodec(framing.BinaryHeader(2))
+			conn, peer := net.Pipe()
+			defer peer.Close()
+
+			nm := NewNetworkManager(tt.packager(), codec, func(ctx context.Context) (io.ReadWriteCloser, error) {
+				return conn, nil
+			})
+			nm.Version = tt.version
+			nm.ResponseTimeout = 100 * time.Millisecond
+			nm.EchoInterval = time.Hour
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() { _ = nm.Run(ctx) }()
+
+			// sign-on from the manager
+			frame, err := codec.ReadFrame(peer)
+			require.NoError(t, err)
+			signOn := NewMessage(tt.packager())
+			require.NoError(t, signOn.Unpack(frame))
+			assert.Equal(t, networkManagementCode(tt.version, NMMSignOn), signOn.GetString(tt.version.NetworkManagementCodeBit()))
+
+			res, err := CreateResponseISO(signOn, tt.approved)
+			require.NoError(t, err)
+			b, err := res.PackISO()
+			require.NoError(t, err)
+			require.NoError(t, codec.WriteFrame(peer, b))
+			require.Eventually(t, nm.IsUp, time.Second, 5*time.Millisecond)
+
+			// echo test from the host
+			echo := NewMessage(tt.packager())
+			echo.SetMtiString(tt.echo)
+			echo.SetString(7, "1018120000").SetString(11, "000099").
+				SetString(tt.version.NetworkManagementCodeBit(), networkManagementCode(tt.version, NMMEchoTest))
+			b, err = echo.PackISO()
+			require.NoError(t, err)
+			require.NoError(t, codec.WriteFrame(peer, b))
+
+			frame, err = codec.ReadFrame(peer)
+			require.NoError(t, err)
+			echoRes := NewMessage(tt.packager())
+			require.NoError(t, echoRes.Unpack(frame))
+			assert.True(t, echoRes.IsResponse())
+			assert.Equal(t, tt.approved, echoRes.GetString(39))
+		})
+	}
+}
+
+func TestNetworkManagerSignOnDeclined(t *testing.T) {
+	codec := framing.NewCodec(framing.BinaryHeader(2))
+	conn, peer := net.Pipe()
+	defer peer.Close()
+
+	nm := NewNetworkManager(DefaultPackager1993(), codec, func(ctx context.Context) (io.ReadWriteCloser, error) {
+		return conn, nil
+	})
+	nm.Version = MTIVersion1993
+	nm.ResponseTimeout = time.Second
+
+	go func() {
+		frame, err := codec.ReadFrame(peer)
+		if err != nil {
+			return
+		}
+		req := NewMessage(DefaultPackager1993())
+		if req.Unpack(frame) != nil {
+			return
+		}
+		res, err := CreateResponseISO(req, "911")
+		if err != nil {
+			return
+		}
+		b, _ := res.PackISO()
+		_ = codec.WriteFrame(peer, b)
+	}()
+
+	err := nm.runSession(context.Background())
+	assert.ErrorIs(t, err, ErrNMMRequestDeclined)
+	assert.False(t, nm.IsUp())
+}
+
+func TestNetworkManagerKeyExchange(t *testing.T) {
+	codec := framing.NewCodec(framing.BinaryHeader(2))
+	conn, peer := net.Pipe()
+	defer peer.Close()
+
+	nm := NewNetworkManager(DefaultPackager(), codec, func(ctx context.Context) (io.ReadWriteCloser, error) {
+		return conn, nil
+	})
+	nm.ResponseTimeout = time.Second
+	nm.EchoInterval = time.Hour
+	nm.KeyExchangeInterval = 10 * time.Millisecond
+	nm.PrepareRequest = func(msg *Message) {
+		if msg.GetString(70) == NMMKeyExchange {
+			msg.SetString(48, "KEY REQUEST")
+		}
+	}
+
+	keys := make(chan *Message, 1)
+	errs := make(chan error, 1)
+	nm.OnKeyExchange = func(res *Message) { keys <- res }
+	nm.OnError = func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = nm.Run(ctx) }()
+
+	// the sign-on is approved, the first key exchange declined and the second approved
+	for i, rc := range []string{ResponseCodeApproved, "91", ResponseCodeApproved} {
+		frame, err := codec.ReadFrame(peer)
+		require.NoError(t, err)
+		req := NewMessage(DefaultPackager())
+		require.NoError(t, req.Unpack(frame))
+		if i > 0 {
+			assert.Equal(t, NMMKeyExchange, req.GetString(70))
+			assert.Equal(t, "KEY REQUEST", req.GetString(48))
+		}
+
+		res, err := CreateResponseISO(req, rc)
+		require.NoError(t, err)
+		res.SetString(48, "KEY "+req.GetString(11))
+		b, err := res.PackISO()
+		require.NoError(t, err)
+		require.NoError(t, codec.WriteFrame(peer, b))
+	}
+
+	select {
+	case err := <-errs:
+		assert.ErrorIs(t, err, ErrNMMRequestDeclined)
+		assert.Contains(t, err.Error(), "key exchange")
+	case <-time.After(time.Second):
+		t.Fatal("the declined key exchange was not reported")
+	}
+	select {
+	case res := <-keys:
+		assert.Equal(t, "KEY 000003", res.GetString(48))
+	case <-time.After(time.Second):
+		t.Fatal("the approved key exchange was not handed over")
+	}
+	assert.True(t, nm.IsUp())
+}
+
+func TestNetworkManagerEchoDisabledAndSignOff(t *testing.T) {
+	codec := framing.NewCodec(framing.BinaryHeader(2))
+	conn, peer := net.Pipe()
+	defer peer.Close()
+
+	nm := NewNetworkManager(DefaultPackager(), codec, func(ctx context.Context) (io.ReadWriteCloser, error) {
+		return conn, nil
+	})
+	nm.EchoInterval = 0
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- nm.Run(ctx) }()
+
+	frame, err := codec.ReadFrame(peer)
+	require.NoError(t, err)
+	signOn := NewMessage(DefaultPackager())
+	require.NoError(t, signOn.Unpack(frame))
+	res, err := CreateResponseISO(signOn, ResponseCodeApproved)
+	require.NoError(t, err)
+	b, err := res.PackISO()
+	require.NoError(t, err)
+	require.NoError(t, codec.WriteFrame(peer, b))
+	require.Eventually(t, nm.IsUp, time.Second, 5*time.Millisecond)
+
+	// the sign-off is read but never answered, Run must not wait for the 30s response timeout
+	go func() { _, _ = codec.ReadFrame(peer) }()
+	cancel()
+	select {
+	case err = <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(2 * signOffTimeout):
+		t.Fatal("Run waited for the sign-off response")
+	}
+}
+
+func TestNetworkManagerReconnectBackoff(t *testing.T) {
+	var dials atomic.Int32
+	nm := NewNetworkManager(DefaultPackager(), framing.NewCodec(framing.BinaryHeader(2)), func(ctx context.Context) (io.ReadWriteCloser, error) {
+		dials.Add(1)
+		return nil, errors.New("connection refused")
+	})
+	nm.ReconnectDelay = 0
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, nm.Run(ctx), context.DeadlineExceeded)
+
+	// 0, 100ms, 300ms, 700ms: the delay doubles from the minimum
+	assert.LessOrEqual(t, dials.Load(), int32(3))
+}
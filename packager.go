@@ -3,27 +3,54 @@ package iso8583
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"strconv"
 )
 
 var isoHeader = []byte("ISO")
 
+// MaxBitNumber is the highest bit number, bits 129 - 192 need a tertiary bitmap
+const MaxBitNumber = 192
+
+// maxSecondaryBitNumber is the highest bit number without a tertiary bitmap
+const maxSecondaryBitNumber = 128
+
 type IsoPackager struct {
 	HasHeader         bool                 `json:"hasHeader"`
 	HeaderLength      int                  `json:"headerLength"`
+	MTIEncoding       Encoding             `json:"mtiEncoding"`       // "ascii", "ebcdic" or "ebcdic1047"
+	BitmapEncoding    Encoding             `json:"bitmapEncoding"`    // "ascii" (hex characters), "binary", "ebcdic" or "ebcdic1047" (hex characters)
+	HasTertiaryBitmap bool                 `json:"hasTertiaryBitmap"` // bit 65 signals a tertiary bitmap for bits 129 - 192
 	MessageKey        []int                `json:"messageKey"`
 	PackagerConfig    map[string]BitConfig `json:"packagerConfig"` // from json
 	MandatoryBit      []int                `json:"mandatoryBit"`
-	IsoPackagerConfig [129]BitConfig
-	PrefixLengths     [129]int // Pre-computed prefix lengths
-	MaxLengths        [129]int // Pre-computed max lengths
+	AllowedMTIs       []MTIType            `json:"allowedMtis"` // empty allows every valid MTI
+	Rules             []MessageRule        `json:"rules"`       // per MTI field rules, see MessageRule
+	IsoPackagerConfig [MaxBitNumber + 1]BitConfig
+	PrefixLengths     [MaxBitNumber + 1]int      // Pre-computed prefix lengths
+	PrefixSizes       [MaxBitNumber + 1]int      // Pre-computed prefix sizes in bytes
+	LengthEncodings   [MaxBitNumber + 1]Encoding // Pre-computed length prefix encodings
+	ValueEncodings    [MaxBitNumber + 1]Encoding // Pre-computed value encodings
+	MaxLengths        [MaxBitNumber + 1]int      // Pre-computed max lengths
+	allowedMTIs       map[MTITypeByte]struct{}
+	validators        [MaxBitNumber + 1][]FieldValidator // registered with RegisterValidator
+	iccRules          []ICCRule                          // added with AddICCRule
+	hashKey           []byte                             // set with SetHashKey
 }
 
 type BitConfig struct {
-	IsMandatory bool      `json:"isMandatory"`
-	Type        BitType   `json:"type"`
-	Length      BitLength `json:"length"`
+	Name        string     `json:"name"` // field name, e.g. "primaryAccountNumber", used by cmd/iso8583gen
+	IsMandatory bool       `json:"isMandatory"`
+	Type        BitType    `json:"type"`
+	Length      BitLength  `json:"length"`
+	Encoding    Encoding   `json:"encoding"`  // value encoding: "ascii" (default), "bcd", "ebcdic" or "ebcdic1047"
+	Padding     Padding    `json:"padding"`   // BCD padding side for odd lengths: "LEFT" (default) or "RIGHT"
+	Filler      string     `json:"filler"`    // BCD filler nibble as a hex digit, default "0"
+	Subfields   *Subfields `json:"subfields"` // internal structure, see GetSubfield and SetSubfield
+	// Sensitivity redacts the field in Redact, MarshalJSON, Dump and validation reports,
+	// empty is "first6-last4" for DE 2, "mask" for DE 35, 36, 45, 52 and 55 and "none" otherwise
+	Sensitivity Sensitivity `json:"sensitivity"`
 }
 
 func NewPackager(r io.Reader) (*IsoPackager, error) {
@@ -38,19 +65,45 @@ func NewPackager(r io.Reader) (*IsoPackager, error) {
 		return nil, errors.Join(err, ErrCreatingNewPackager)
 	}
 
+	switch packager.MTIEncoding {
+	case "":
+		packager.MTIEncoding = EncodingASCII
+	case EncodingASCII, EncodingEBCDIC, EncodingEBCDIC1047:
+	default:
+		return nil, errors.Join(ErrInvalidEncoding, ErrCreatingNewPackager)
+	}
+
+	switch packager.BitmapEncoding {
+	case "":
+		packager.BitmapEncoding = EncodingASCII
+	case EncodingASCII, EncodingBinary, EncodingEBCDIC, EncodingEBCDIC1047:
+	default:
+		return nil, errors.Join(ErrInvalidEncoding, ErrCreatingNewPackager)
+	}
+
+	packager.SetAllowedMTIs(packager.AllowedMTIs...)
+
+	for i := range packager.Rules {
+		if err = packager.Rules[i].compile(); err != nil {
+			return nil, errors.Join(err, ErrCreatingNewPackager)
+		}
+	}
+
 	packager.MandatoryBit = make([]int, 0)
 	for k, v := range packager.PackagerConfig {
 		key, err := strconv.Atoi(k)
 		if err != nil {
 			return nil, errors.Join(err, ErrCreatingNewPackager)
 		}
-		packager.IsoPackagerConfig[key] = v
+		if key > maxSecondaryBitNumber && !packager.HasTertiaryBitmap {
+			return nil, errors.Join(fmt.Errorf("%w: %d needs a tertiary bitmap", ErrInvalidBitNumber, key), ErrCreatingNewPackager)
+		}
+		if err = packager.setBitConfig(key, v); err != nil {
+			return nil, errors.Join(err, ErrCreatingNewPackager)
+		}
 		if v.IsMandatory {
 			packager.MandatoryBit = append(packager.MandatoryBit, key)
 		}
-		// Pre-compute values for faster access
-		packager.PrefixLengths[key] = v.Length.Type.GetPrefixLen()
-		packager.MaxLengths[key] = v.Length.Max
 	}
 
 	// clear packager config that read from reader
@@ -59,6 +112,114 @@ func NewPackager(r io.Reader) (*IsoPackager, error) {
 	return &packager, nil
 }
 
+// SetAllowedMTIs restricts the MTIs accepted by Unpack, no MTIs allows every valid MTI
+func (p *IsoPackager) SetAllowedMTIs(mtis ...MTIType) {
+	p.AllowedMTIs = mtis
+	p.allowedMTIs = nil
+	if len(mtis) == 0 {
+		return
+	}
+	p.allowedMTIs = make(map[MTITypeByte]struct{}, len(mtis))
+	for _, mti := range mtis {
+		p.allowedMTIs[mti.ToMtiByte()] = struct{}{}
+	}
+}
+
+// IsAllowedMti checks the MTI is valid and allowed by the packager
+func (p *IsoPackager) IsAllowedMti(mti MTITypeByte) bool {
+	if !mti.IsValid() {
+		return false
+	}
+	if p.allowedMTIs == nil {
+		return true
+	}
+	_, ok := p.allowedMTIs[mti]
+	return ok
+}
+
+// SetBitConfig replaces the config of a bit and pre-computes the values used while packing
+// and unpacking, e.g. to adjust a preset. Set the bits before the packager is shared.
+func (p *IsoPackager) SetBitConfig(bit int, v BitConfig) error {
+	if bit < 2 || bit > MaxBitNumber {
+		return fmt.Errorf("%w: %d", ErrInvalidBitNumber, bit)
+	}
+	if bit > maxSecondaryBitNumber && !p.HasTertiaryBitmap {
+		return fmt.Errorf("%w: %d needs a tertiary bitmap", ErrInvalidBitNumber, bit)
+	}
+	if err := p.setBitConfig(bit, v); err != nil {
+		return err
+	}
+	p.MandatoryBit = p.GetMandatoryBitsFromConfig()
+	return nil
+}
+
+// setBitConfig stores the config of a bit and pre-computes the values used
+// while packing and unpacking
+func (p *IsoPackager) setBitConfig(bit int, v BitConfig) error {
+	if bit < 0 || bit >= len(p.IsoPackagerConfig) {
+		return fmt.Errorf("%w: %d", ErrInvalidBitNumber, bit)
+	}
+
+	switch v.Length.Encoding {
+	case "":
+		v.Length.Encoding = EncodingASCII
+	case EncodingASCII, EncodingBCD, EncodingBinary, EncodingEBCDIC, EncodingEBCDIC1047:
+	default:
+		return fmt.Errorf("%w: length encoding %q for bit %d", ErrInvalidEncoding, v.Length.Encoding, bit)
+	}
+
+	switch v.Encoding {
+	case "":
+		v.Encoding = EncodingASCII
+	case EncodingASCII, EncodingBCD, EncodingEBCDIC, EncodingEBCDIC1047:
+	default:
+		return fmt.Errorf("%w: value encoding %q for bit %d", ErrInvalidEncoding, v.Encoding, bit)
+	}
+
+	if v.Padding == "" {
+		v.Padding = PaddingLeft
+	}
+	if v.Filler == "" {
+		v.Filler = "0"
+	}
+	if _, ok := hexNibble(v.Filler[0]); !ok || len(v.Filler) != 1 {
+		return fmt.Errorf("%w: filler %q for bit %d", ErrInvalidEncoding, v.Filler, bit)
+	}
+
+	if v.Sensitivity == "" {
+		v.Sensitivity = defaultSensitivities[bit]
+		if v.Sensitivity == "" {
+			v.Sensitivity = SensitivityNone
+		}
+	}
+	if !v.Sensitivity.isValid() {
+		return fmt.Errorf("%w: %q for bit %d", ErrInvalidSensitivity, v.Sensitivity, bit)
+	}
+
+	if prefixMax := v.Length.MaxPrefixValue(); prefixMax > 0 && v.Length.Max > prefixMax {
+		return fmt.Errorf("%w: max length %d of bit %d does not fit its %s %s prefix of at most %d",
+			ErrInvalidPackager, v.Length.Max, bit, v.Length.Encoding, v.Length.Type, prefixMax)
+	}
+
+	if v.Subfields != nil {
+		subfields, err := v.Subfields.compile(bit, v.Length.Max)
+		if err != nil {
+			return err
+		}
+		v.Subfields = subfields
+	}
+
+	p.IsoPackagerConfig[bit] = v
+
+	// Pre-compute values for faster access
+	p.PrefixLengths[bit] = v.Length.Type.GetPrefixLen()
+	p.PrefixSizes[bit] = v.Length.GetPrefixSize()
+	p.LengthEncodings[bit] = v.Length.Encoding
+	p.ValueEncodings[bit] = v.Encoding
+	p.MaxLengths[bit] = v.Length.Max
+	return nil
+}
+
 func (p *IsoPackager) GetMandatoryBitsFromConfig() []int {
 	mandatoryBit := make([]int, 0)
 	for k, v := range p.IsoPackagerConfig {
@@ -68,3 +229,11 @@ func (p *IsoPackager) GetMandatoryBitsFromConfig() []int {
 	}
 	return mandatoryBit
 }
+
+// bitmapLength returns the number of bytes a single 64-bit bitmap takes on the wire
+func (p *IsoPackager) bitmapLength() int {
+	if p.BitmapEncoding == EncodingBinary {
+		return BinaryBitmapLength
+	}
+	return BitmapLength
+}
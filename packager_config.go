@@ -8,8 +8,53 @@ import (
 )
 
 type BitLength struct {
-	Type LengthType `json:"type"` // "FIXED", "LLVAR", "LLLVAR", "LLLLVAR"
-	Max  int        `json:"max"`  // max length (or exact length if FIXED)
+	Type     LengthType `json:"type"`     // "FIXED", "LLVAR", "LLLVAR", "LLLLVAR"
+	Max      int        `json:"max"`      // max length (or exact length if FIXED)
+	Encoding Encoding   `json:"encoding"` // length prefix encoding: "ascii" (default), "bcd", "binary", "ebcdic", "ebcdic1047"
+}
+
+// GetPrefixSize returns the number of bytes the length prefix takes on the wire.
+// ASCII and EBCDIC use one byte per digit, BCD packs two digits per byte and
+// binary uses a 1 byte (LLVAR) or 2 byte (LLLVAR, LLLLVAR) big-endian integer.
+// Fixed fields have no prefix.
+func (bl *BitLength) GetPrefixSize() int {
+	digits := bl.Type.GetPrefixLen()
+	if digits == FixedLength {
+		return 0
+	}
+	switch bl.Encoding {
+	case EncodingBCD:
+		return (digits + 1) / 2
+	case EncodingBinary:
+		if digits == LLVarLength {
+			return 1
+		}
+		return 2
+	default:
+		return digits
+	}
+}
+
+// MaxPrefixValue returns the largest length the prefix can hold on the wire:
+// 10^digits - 1 for ASCII and EBCDIC, two digits per byte for BCD and
+// 256^bytes - 1 for binary. Fixed fields have no prefix and return 0.
+func (bl *BitLength) MaxPrefixValue() int {
+	size := bl.GetPrefixSize()
+	if size == 0 {
+		return 0
+	}
+	digits := size
+	switch bl.Encoding {
+	case EncodingBCD:
+		digits = size * 2
+	case EncodingBinary:
+		return 1<<(8*size) - 1
+	}
+	n := 1
+	for range digits {
+		n *= 10
+	}
+	return n - 1
 }
 
 type LengthType string
@@ -60,6 +105,59 @@ func (lt *LengthType) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// Encoding describes how a part of the message is represented on the wire.
+type Encoding string
+
+const (
+	EncodingASCII      Encoding = "ascii"      // ASCII characters, bitmap as hex characters
+	EncodingBinary     Encoding = "binary"     // raw bytes, lengths as big-endian integers
+	EncodingBCD        Encoding = "bcd"        // packed BCD, two digits per byte
+	EncodingEBCDIC     Encoding = "ebcdic"     // EBCDIC code page 037
+	EncodingEBCDIC1047 Encoding = "ebcdic1047" // EBCDIC code page 1047
+)
+
+// UnmarshalJSON Implement json.Unmarshaler
+func (e *Encoding) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	s = strings.ToLower(s)
+	encoding := Encoding(s)
+	switch encoding {
+	case EncodingASCII, EncodingBinary, EncodingBCD, EncodingEBCDIC, EncodingEBCDIC1047:
+		*e = encoding
+	default:
+		return ErrInvalidEncoding
+	}
+	return nil
+}
+
+// Padding is the side a BCD value is padded on when it has an odd number of digits
+type Padding string
+
+const (
+	PaddingLeft  Padding = "LEFT"
+	PaddingRight Padding = "RIGHT"
+)
+
+// UnmarshalJSON Implement json.Unmarshaler
+func (pd *Padding) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	s = strings.ToUpper(s)
+	padding := Padding(s)
+	switch padding {
+	case PaddingLeft, PaddingRight:
+		*pd = padding
+	default:
+		return ErrInvalidPadding
+	}
+	return nil
+}
+
 type BitType string
 
 const (
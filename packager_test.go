@@ -0,0 +1,89 @@
+package iso8583
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestPackager creates a packager from the packagerConfig entries, e.g.
+// `"2": {"type": "n", "length": {"type": "LLVAR", "max": 19}}`
+func newTestPackager(t *testing.T, options string, fields ...string) *IsoPackager {
+	t.Helper()
+	config := fmt.Sprintf(`{%s"packagerConfig": {%s}}`, options, strings.Join(fields, ","))
+	packager, err := NewPackager(strings.NewReader(config))
+	require.NoError(t, err)
+	return packager
+}
+
+func TestNewPackagerRejectsMaxOverPrefix(t *testing.T) {
+	tests := []struct {
+		name   string
+		length string
+		valid  bool
+	}{
+		{"ascii LLVAR 99", `{"type": "LLVAR", "max": 99}`, true},
+		{"ascii LLVAR 100", `{"type": "LLVAR", "max": 100}`, false},
+		{"ascii LLLVAR 1000", `{"type": "LLLVAR", "max": 1000}`, false},
+		{"ebcdic LLVAR 100", `{"type": "LLVAR", "max": 100, "encoding": "ebcdic"}`, false},
+		{"bcd LLVAR 99", `{"type": "LLVAR", "max": 99, "encoding": "bcd"}`, true},
+		{"bcd LLVAR 100", `{"type": "LLVAR", "max": 100, "encoding": "bcd"}`, false},
+		{"binary LLVAR 255", `{"type": "LLVAR", "max": 255, "encoding": "binary"}`, true},
+		{"binary LLVAR 300", `{"type": "LLVAR", "max": 300, "encoding": "binary"}`, false},
+		{"binary LLLVAR 999", `{"type": "LLLVAR", "max": 999, "encoding": "binary"}`, true},
+		{"fixed 1000", `{"type": "FIXED", "max": 1000}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := `{"packagerConfig": {"48": {"type": "ans", "length": ` + tt.length + `}}}`
+			_, err := NewPackager(strings.NewReader(config))
+			if tt.valid {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidPackager)
+			assert.ErrorIs(t, err, ErrCreatingNewPackager)
+		})
+	}
+}
+
+func TestPackLengthOverflowsPrefix(t *testing.T) {
+	packager := newTestPackager(t, "", `"48": {"type": "ans", "length": {"type": "LLVAR", "max": 255, "encoding": "binary"}}`)
+	// a config changed after NewPackager is not checked again
+	packager.MaxLengths[48] = 300
+
+	msg := NewMessage(packager)
+	msg.SetMtiString("0200")
+	msg.SetString(48, strings.Repeat("A", 260))
+
+	_, err := msg.PackISO()
+	assert.ErrorContains(t, err, "does not fit")
+}
+
+func TestSetBitConfig(t *testing.T) {
+	packager := DefaultPackager2003()
+	require.NoError(t, packager.SetBitConfig(41, NewBitConfigFixed(true, BitTypeANS, 16)))
+	assert.Equal(t, 16, packager.MaxLengths[41])
+	assert.Contains(t, packager.MandatoryBit, 41)
+
+	msg := NewMessage(packager)
+	msg.SetMtiString("2200")
+	msg.SetString(41, "TERMINAL00000001")
+	b, err := msg.PackISO()
+	require.NoError(t, err)
+
+	out := NewMessage(packager)
+	require.NoError(t, out.Unpack(b))
+	assert.Equal(t, "TERMINAL00000001", out.GetString(41))
+	assert.Equal(t, 8, DefaultPackager1993().MaxLengths[41], "the presets are not shared")
+
+	assert.ErrorIs(t, packager.SetBitConfig(1, NewBitConfigFixed(false, BitTypeN, 3)), ErrInvalidBitNumber)
+	assert.ErrorIs(t, packager.SetBitConfig(130, NewBitConfigFixed(false, BitTypeN, 3)), ErrInvalidBitNumber)
+
+	tooLong := NewBitConfigLLVar(false, BitTypeANS, 99)
+	tooLong.Length.Max = 100
+	assert.ErrorIs(t, packager.SetBitConfig(43, tooLong), ErrInvalidPackager)
+}
@@ -0,0 +1,181 @@
+package iso8583
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DE 90 original data elements layout: original MTI, original STAN (DE 11),
+// original transmission date and time (DE 7), original acquiring institution
+// id (DE 32) and original forwarding institution id (DE 33)
+const (
+	originalDataElementsLength = 42
+	originalInstitutionLength  = 11
+)
+
+var ErrReversalNotAcknowledged = errors.New("reversal not acknowledged")
+
+// Sender sends a request and waits for its response, Client implements it
+type Sender interface {
+	Send(ctx context.Context, req *Message) (*Message, error)
+}
+
+// NewReversal builds a reversal of the original request, a 0400 with DE 90 or
+// for ISO 8583:1993 and 2003 messages an x420 with DE 56.
+// The original fields are copied, the response fields 38 and 39 are dropped
+// and the original data elements are filled in.
+func NewReversal(original *Message) (*Message, error) {
+	if !original.IsTransactional() || !original.IsRequest() || original.IsReversal() {
+		return nil, fmt.Errorf("%w: cannot reverse %s", ErrNotRequestMti, original.MTI)
+	}
+
+	de90, err := FormatOriginalDataElements(original)
+	if err != nil {
+		return nil, err
+	}
+
+	version := original.MTI.Version()
+	msg := CloneMessage(original)
+	msg.SetMTIByte(version.Reversal())
+	msg.Unset(38).Unset(39)
+	msg.SetString(version.OriginalDataElementsBit(), de90)
+	return msg, nil
+}
+
+// FormatOriginalDataElements formats the original data elements of a reversal of the original message.
+// DE 90 (1987): MTI (4), STAN (6), transmission date and time (10),
+// acquiring institution id (11) and forwarding institution id (11).
+// DE 56 (1993 and 2003): MTI (4), STAN (6), local date and time (12)
+// and LLVAR acquiring institution id (..11).
+func FormatOriginalDataElements(original *Message) (string, error) {
+	if original.MTI.Version().is1993() {
+		return formatOriginalDataElements1993(original), nil
+	}
+
+	if original.packager != nil && original.packager.MaxLengths[90] != originalDataElementsLength {
+		return "", fmt.Errorf("%w: bit %d must be %d long", ErrInvalidPackager, 90, originalDataElementsLength)
+	}
+
+	var b [originalDataElementsLength]byte
+	pos := copy(b[:], original.MTI[:])
+	pos += copyRightAligned(b[pos:pos+6], original.GetByte(11))
+	pos += copyRightAligned(b[pos:pos+10], original.GetByte(7))
+	pos += copyRightAligned(b[pos:pos+originalInstitutionLength], original.GetByte(32))
+	copyRightAligned(b[pos:pos+originalInstitutionLength], original.GetByte(33))
+	return string(b[:]), nil
+}
+
+func formatOriginalDataElements1993(original *Message) string {
+	acquirer := original.GetByte(32)
+	if len(acquirer) > originalInstitutionLength {
+		acquirer = acquirer[:originalInstitutionLength]
+	}
+
+	var b [4 + 6 + 12 + 2 + originalInstitutionLength]byte
+	pos := copy(b[:], original.MTI[:])
+	pos += copyRightAligned(b[pos:pos+6], original.GetByte(11))
+	pos += copyRightAligned(b[pos:pos+12], original.GetByte(12))
+	pos += copy(b[pos:], fourDigitTable[len(acquirer)][2:])
+	pos += copy(b[pos:], acquirer)
+	return string(b[:pos])
+}
+
+// copyRightAligned copies src into dst right aligned and zero padded,
+// src longer than dst is truncated from the left
+func copyRightAligned(dst, src []byte) int {
+	if len(src) > len(dst) {
+		src = src[len(src)-len(dst):]
+	}
+	pad := len(dst) - len(src)
+	for i := 0; i < pad; i++ {
+		dst[i] = '0'
+	}
+	copy(dst[pad:], src)
+	return len(dst)
+}
+
+// Reverser sends reversals of timed out requests.
+// The 0400 (x420) is repeated as 0401 (x421) on RetrySchedule until it is acknowledged.
+type Reverser struct {
+	Sender Sender
+	// ResponseTimeout is the time to wait for each 0410
+	ResponseTimeout time.Duration
+	// RetrySchedule is the delay before each 0401 repeat, its length is the number of repeats
+	RetrySchedule []time.Duration
+	// PrepareReversal is called before each 0400 and 0401 is sent, e.g. to set DE 39 reason code
+	PrepareReversal func(msg *Message)
+	// OnError is called with the error of a background reversal
+	OnError func(err error)
+}
+
+// NewReverser creates a reverser with a 30s response timeout and 3 repeats
+func NewReverser(sender Sender) *Reverser {
+	return &Reverser{
+		Sender:          sender,
+		ResponseTimeout: 30 * time.Second,
+		RetrySchedule:   []time.Duration{10 * time.Second, 30 * time.Second, 60 * time.Second},
+	}
+}
+
+// Send sends the request, when it times out its reversal is sent in the background
+// and the timeout error is returned
+func (r *Reverser) Send(ctx context.Context, req *Message) (*Message, error) {
+	sendCtx, cancel := context.WithTimeout(ctx, r.ResponseTimeout)
+	defer cancel()
+
+	res, err := r.Sender.Send(sendCtx, req)
+	if err == nil || !errors.Is(err, context.DeadlineExceeded) || !req.IsTransactional() {
+		return res, err
+	}
+
+	go func() {
+		if _, errRev := r.Reverse(context.WithoutCancel(ctx), req); errRev != nil && r.OnError != nil {
+			r.OnError(errRev)
+		}
+	}()
+	return nil, err
+}
+
+// Reverse sends the reversal of the original request and repeats it
+// until it is acknowledged or the retry schedule is exhausted
+func (r *Reverser) Reverse(ctx context.Context, original *Message) (*Message, error) {
+	msg, err := NewReversal(original)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		if r.PrepareReversal != nil {
+			r.PrepareReversal(msg)
+		}
+		if err = msg.ValidateMandatoryBits(); err != nil {
+			return nil, err
+		}
+
+		res, errSend := r.send(ctx, msg)
+		if errSend == nil {
+			return res, nil
+		}
+		err = errors.Join(err, errSend)
+
+		if attempt >= len(r.RetrySchedule) || ctx.Err() != nil {
+			return nil, errors.Join(ErrReversalNotAcknowledged, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, errors.Join(ErrReversalNotAcknowledged, err, ctx.Err())
+		case <-time.After(r.RetrySchedule[attempt]):
+		}
+
+		msg.MTI[3] = byte(MTIOriginAcquirerRepeat)
+	}
+}
+
+func (r *Reverser) send(ctx context.Context, msg *Message) (*Message, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.ResponseTimeout)
+	defer cancel()
+	return r.Sender.Send(ctx, msg)
+}
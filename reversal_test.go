@@ -0,0 +1,115 @@
+package iso8583
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewReversal(t *testing.T) {
+	req := newTestRequest("000123")
+	req.SetString(32, "12345").SetString(38, "ABC123").SetString(39, "00")
+
+	rev, err := NewReversal(req)
+	require.NoError(t, err)
+	assert.Equal(t, MTIReversalRequestByte, rev.MTI)
+	assert.False(t, rev.HasBit(38))
+	assert.False(t, rev.HasBit(39))
+	assert.Equal(t, "4111111111111111", rev.GetString(2))
+	assert.Equal(t, "0200"+"000123"+"1018120000"+"00000012345"+"00000000000", rev.GetString(90))
+	assert.Equal(t, MTIFinancialRequestByte, req.MTI, "the original is left unchanged")
+}
+
+func TestNewReversal1993(t *testing.T) {
+	req := NewMessage(DefaultPackager1993())
+	req.SetMtiString("1200")
+	req.SetString(11, "000123").SetString(12, "251018120000").SetString(32, "123456789012")
+
+	rev, err := NewReversal(req)
+	require.NoError(t, err)
+	assert.Equal(t, MTI1993ReversalAdvice.ToMtiByte(), rev.MTI)
+	assert.Equal(t, "1200"+"000123"+"251018120000"+"11"+"12345678901", rev.GetString(56))
+}
+
+func TestNewReversalRejectsNonRequests(t *testing.T) {
+	for _, mti := range []MTIType{"0210", "0400", "0800"} {
+		msg := newTestRequest("000123")
+		msg.SetMtiString(mti)
+		_, err := NewReversal(msg)
+		assert.ErrorIs(t, err, ErrNotRequestMti, "mti %s", mti)
+	}
+}
+
+// fakeSender answers with the results in order, the last one is repeated
+type fakeSender struct {
+	mu      sync.Mutex
+	sent    []MTITypeByte
+	results []error
+}
+
+func (s *fakeSender) Send(ctx context.Context, req *Message) (*Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, req.MTI)
+
+	err := s.results[min(len(s.sent), len(s.results))-1]
+	if err != nil {
+		return nil, err
+	}
+	res := CloneMessage(req)
+	if err = res.SetMTIResponse(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *fakeSender) sentMTIs() []MTITypeByte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]MTITypeByte(nil), s.sent...)
+}
+
+func TestReverserSendReversesTimeout(t *testing.T) {
+	sender := &fakeSender{results: []error{context.DeadlineExceeded, errors.New("no response"), nil}}
+	reverser := NewReverser(sender)
+	reverser.RetrySchedule = []time.Duration{time.Millisecond, time.Millisecond}
+
+	errs := make(chan error, 1)
+	reverser.OnError = func(err error) { errs <- err }
+
+	_, err := reverser.Send(context.Background(), newTestRequest("000123"))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	require.Eventually(t, func() bool { return len(sender.sentMTIs()) == 3 }, time.Second, time.Millisecond)
+	assert.Equal(t, []MTITypeByte{MTIFinancialRequestByte, MTIReversalRequestByte, MTIRepeatedReversalRequestByte}, sender.sentMTIs())
+	select {
+	case err = <-errs:
+		t.Fatalf("unexpected reversal error: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestReverserReverseNotAcknowledged(t *testing.T) {
+	sender := &fakeSender{results: []error{errors.New("no response")}}
+	reverser := NewReverser(sender)
+	reverser.RetrySchedule = []time.Duration{time.Millisecond}
+
+	_, err := reverser.Reverse(context.Background(), newTestRequest("000123"))
+	assert.ErrorIs(t, err, ErrReversalNotAcknowledged)
+	assert.Equal(t, []MTITypeByte{MTIReversalRequestByte, MTIRepeatedReversalRequestByte}, sender.sentMTIs())
+}
+
+func TestReverserSendDoesNotReverseOtherErrors(t *testing.T) {
+	sender := &fakeSender{results: []error{ErrClientClosed}}
+	reverser := NewReverser(sender)
+
+	_, err := reverser.Send(context.Background(), newTestRequest("000123"))
+	assert.ErrorIs(t, err, ErrClientClosed)
+	time.Sleep(10 * time.Millisecond)
+	assert.Len(t, sender.sentMTIs(), 1)
+}
@@ -0,0 +1,314 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidRule   = errors.New("invalid rule")
+	ErrRuleViolation = errors.New("rule violation")
+)
+
+// Rule names of a RuleViolation
+const (
+	RuleMandatory   = "mandatory"
+	RuleForbidden   = "forbidden"
+	RuleConditional = "conditional"
+	RuleNotAllowed  = "not allowed"
+)
+
+// MessageRule defines the fields of the messages matching the MTI pattern
+//
+//	{
+//	  "mti": "0200",
+//	  "mandatory": [2, 3, 4, 7, 11],
+//	  "optional": [12, 13, 22, 14],
+//	  "forbidden": [39],
+//	  "conditional": [{"bit": 14, "when": "22 ^= 01"}]
+//	}
+type MessageRule struct {
+	// MTI is the MTI or a pattern with x as wildcard digit e.g. "02x0" or "x8xx",
+	// the most specific matching rule is applied
+	MTI string `json:"mti"`
+	// Name is the message profile name, the type name generated by cmd/iso8583gen
+	Name      string `json:"name"`
+	Mandatory []int  `json:"mandatory"`
+	// Optional bits are allowed, when set every bit that is not mandatory,
+	// optional or conditional is rejected
+	Optional  []int `json:"optional"`
+	Forbidden []int `json:"forbidden"`
+	// Conditional bits are mandatory when their expression is true
+	Conditional []ConditionalRule `json:"conditional"`
+}
+
+// ConditionalRule makes the bit mandatory when the expression is true.
+// An expression is one or more conditions joined with && and ||, && binds first:
+//
+//	22 present          bit 22 is set
+//	22 absent           bit 22 is not set
+//	22 == 051           bit 22 equals 051
+//	22 != 051           bit 22 does not equal 051
+//	22 ^= 01            bit 22 begins with 01
+//	3 in 000000,010000  bit 3 is one of the values
+type ConditionalRule struct {
+	Bit  int    `json:"bit"`
+	When string `json:"when"`
+
+	condition [][]condition // compiled When, OR of ANDs
+}
+
+// RuleViolation is a bit that breaks a rule
+type RuleViolation struct {
+	Bit    int
+	Rule   string
+	Detail string
+	Value  string // offending value, masked when sensitive, set by Message.Validate
+}
+
+func (v *RuleViolation) Error() string {
+	if v.Detail == "" {
+		return fmt.Sprintf("bit %d %s", v.Bit, v.Rule)
+	}
+	return fmt.Sprintf("bit %d %s: %s", v.Bit, v.Rule, v.Detail)
+}
+
+func (v *RuleViolation) Unwrap() error {
+	return ErrRuleViolation
+}
+
+type conditionOp string
+
+const (
+	opPresent   conditionOp = "present"
+	opAbsent    conditionOp = "absent"
+	opEqual     conditionOp = "=="
+	opNotEqual  conditionOp = "!="
+	opHasPrefix conditionOp = "^="
+	opIn        conditionOp = "in"
+)
+
+type condition struct {
+	bit    int
+	op     conditionOp
+	values []string
+}
+
+// AddRule compiles and adds a rule to the packager
+func (p *IsoPackager) AddRule(rule MessageRule) error {
+	if err := rule.compile(); err != nil {
+		return err
+	}
+	p.Rules = append(p.Rules, rule)
+	return nil
+}
+
+// RuleFor returns the most specific rule matching the MTI, nil when there is none
+func (p *IsoPackager) RuleFor(mti MTITypeByte) *MessageRule {
+	var (
+		best          *MessageRule
+		bestWildcards = len(mti) + 1
+	)
+	for i := range p.Rules {
+		wildcards, ok := matchMtiPattern(p.Rules[i].MTI, mti)
+		if ok && wildcards < bestWildcards {
+			best, bestWildcards = &p.Rules[i], wildcards
+		}
+	}
+	return best
+}
+
+// Validate returns every violation of the rule by the message
+func (r *MessageRule) Validate(m *Message) []*RuleViolation {
+	var violations []*RuleViolation
+
+	for _, bit := range r.Mandatory {
+		if !m.HasBit(bit) {
+			violations = append(violations, &RuleViolation{Bit: bit, Rule: RuleMandatory, Detail: "missing"})
+		}
+	}
+
+	for _, bit := range r.Forbidden {
+		if m.HasBit(bit) {
+			violations = append(violations, &RuleViolation{Bit: bit, Rule: RuleForbidden, Detail: "present"})
+		}
+	}
+
+	for i := range r.Conditional {
+		c := &r.Conditional[i]
+		if !m.HasBit(c.Bit) && c.evaluate(m) {
+			violations = append(violations, &RuleViolation{Bit: c.Bit, Rule: RuleConditional, Detail: "missing when " + c.When})
+		}
+	}
+
+	// a forbidden bit is reported once, as forbidden
+	if r.Optional != nil {
+		for i := 0; i < m.activeCount; i++ {
+			bit := m.activeBits[i]
+			if !r.allows(bit) && !slices.Contains(r.Forbidden, bit) {
+				violations = append(violations, &RuleViolation{Bit: bit, Rule: RuleNotAllowed, Detail: "present"})
+			}
+		}
+	}
+
+	return violations
+}
+
+// allows reports whether the bit is mandatory, optional or conditional
+func (r *MessageRule) allows(bit int) bool {
+	if slices.Contains(r.Mandatory, bit) || slices.Contains(r.Optional, bit) {
+		return true
+	}
+	return slices.ContainsFunc(r.Conditional, func(c ConditionalRule) bool {
+		return c.Bit == bit
+	})
+}
+
+func (r *MessageRule) compile() error {
+	if len(r.MTI) != len(MTITypeByte{}) {
+		return fmt.Errorf("%w: mti pattern %q", ErrInvalidRule, r.MTI)
+	}
+	for _, c := range r.MTI {
+		if (c < '0' || c > '9') && c != 'x' && c != 'X' {
+			return fmt.Errorf("%w: mti pattern %q", ErrInvalidRule, r.MTI)
+		}
+	}
+
+	for i := range r.Conditional {
+		c := &r.Conditional[i]
+		if err := validRuleBit(c.Bit); err != nil {
+			return err
+		}
+		condition, err := parseExpression(c.When)
+		if err != nil {
+			return fmt.Errorf("%w: bit %d: %w", ErrInvalidRule, c.Bit, err)
+		}
+		c.condition = condition
+	}
+
+	for _, bits := range [][]int{r.Mandatory, r.Optional, r.Forbidden} {
+		for _, bit := range bits {
+			if err := validRuleBit(bit); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *ConditionalRule) evaluate(m *Message) bool {
+	for _, and := range c.condition {
+		ok := true
+		for _, cond := range and {
+			if !cond.evaluate(m) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *condition) evaluate(m *Message) bool {
+	value := m.GetString(c.bit)
+	switch c.op {
+	case opPresent:
+		return m.HasBit(c.bit)
+	case opAbsent:
+		return !m.HasBit(c.bit)
+	case opEqual:
+		return m.HasBit(c.bit) && value == c.values[0]
+	case opNotEqual:
+		return !m.HasBit(c.bit) || value != c.values[0]
+	case opHasPrefix:
+		return m.HasBit(c.bit) && strings.HasPrefix(value, c.values[0])
+	case opIn:
+		return m.HasBit(c.bit) && slices.Contains(c.values, value)
+	default:
+		return false
+	}
+}
+
+// parseExpression parses the conditions joined with && and ||
+func parseExpression(expr string) ([][]condition, error) {
+	var result [][]condition
+	for _, or := range strings.Split(expr, "||") {
+		var and []condition
+		for _, s := range strings.Split(or, "&&") {
+			cond, err := parseCondition(strings.TrimSpace(s))
+			if err != nil {
+				return nil, err
+			}
+			and = append(and, cond)
+		}
+		result = append(result, and)
+	}
+	return result, nil
+}
+
+// parseCondition parses "<bit> <op> [<value>]"
+func parseCondition(s string) (condition, error) {
+	parts := strings.Fields(s)
+	if len(parts) < 2 {
+		return condition{}, fmt.Errorf("invalid condition %q", s)
+	}
+
+	bit, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return condition{}, fmt.Errorf("invalid condition bit %q", parts[0])
+	}
+	if err = validRuleBit(bit); err != nil {
+		return condition{}, err
+	}
+
+	cond := condition{bit: bit, op: conditionOp(parts[1])}
+	switch cond.op {
+	case opPresent, opAbsent:
+		if len(parts) != 2 {
+			return condition{}, fmt.Errorf("invalid condition %q", s)
+		}
+	case opEqual, opNotEqual, opHasPrefix:
+		if len(parts) != 3 {
+			return condition{}, fmt.Errorf("invalid condition %q", s)
+		}
+		cond.values = parts[2:]
+	case opIn:
+		if len(parts) != 3 {
+			return condition{}, fmt.Errorf("invalid condition %q", s)
+		}
+		cond.values = strings.Split(parts[2], ",")
+	default:
+		return condition{}, fmt.Errorf("invalid condition operator %q", parts[1])
+	}
+	return cond, nil
+}
+
+// matchMtiPattern matches the MTI against a pattern with x wildcards
+// and returns the number of wildcards used
+func matchMtiPattern(pattern string, mti MTITypeByte) (wildcards int, ok bool) {
+	if len(pattern) != len(mti) {
+		return 0, false
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == 'x' || c == 'X':
+			wildcards++
+		case c != mti[i]:
+			return 0, false
+		}
+	}
+	return wildcards, true
+}
+
+func validRuleBit(bit int) error {
+	if bit < 2 || bit > MaxBitNumber {
+		return fmt.Errorf("%w: %w %d", ErrInvalidRule, ErrInvalidBitNumber, bit)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package iso8583
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddRuleCompile(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  MessageRule
+		valid bool
+	}{
+		{"pattern", MessageRule{MTI: "02x0", Mandatory: []int{2}}, true},
+		{"conditions", MessageRule{MTI: "0200", Conditional: []ConditionalRule{
+			{Bit: 14, When: "22 ^= 01 && 35 absent || 3 in 000000,010000"},
+		}}, true},
+		{"short mti", MessageRule{MTI: "020"}, false},
+		{"mti letter", MessageRule{MTI: "02A0"}, false},
+		{"bit 1", MessageRule{MTI: "0200", Mandatory: []int{1}}, false},
+		{"bit 193", MessageRule{MTI: "0200", Forbidden: []int{193}}, false},
+		{"unknown operator", MessageRule{MTI: "0200", Conditional: []ConditionalRule{{Bit: 14, When: "22 ~ 01"}}}, false},
+		{"missing value", MessageRule{MTI: "0200", Conditional: []ConditionalRule{{Bit: 14, When: "22 =="}}}, false},
+		{"extra value", MessageRule{MTI: "0200", Conditional: []ConditionalRule{{Bit: 14, When: "22 present 01"}}}, false},
+		{"condition bit", MessageRule{MTI: "0200", Conditional: []ConditionalRule{{Bit: 14, When: "x present"}}}, false},
+		{"empty condition", MessageRule{MTI: "0200", Conditional: []ConditionalRule{{Bit: 14, When: "22 present &&"}}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := DefaultPackager().AddRule(tt.rule)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidRule)
+			}
+		})
+	}
+}
+
+func TestRuleForMostSpecific(t *testing.T) {
+	packager := DefaultPackager()
+	for _, mti := range []string{"xxxx", "02x0", "0200"} {
+		require.NoError(t, packager.AddRule(MessageRule{MTI: mti}))
+	}
+
+	assert.Equal(t, "0200", packager.RuleFor(MTIType("0200").ToMtiByte()).MTI)
+	assert.Equal(t, "02x0", packager.RuleFor(MTIType("0220").ToMtiByte()).MTI)
+	assert.Equal(t, "xxxx", packager.RuleFor(MTIType("0800").ToMtiByte()).MTI)
+
+	assert.Nil(t, DefaultPackager().RuleFor(MTIType("0200").ToMtiByte()))
+}
+
+func TestMessageRuleValidate(t *testing.T) {
+	rule := MessageRule{
+		MTI:       "0200",
+		Mandatory: []int{2, 3, 4},
+		Optional:  []int{7, 11, 12, 13, 22, 37, 41},
+		Forbidden: []int{39},
+		Conditional: []ConditionalRule{
+			{Bit: 14, When: "22 ^= 01"},
+			{Bit: 35, When: "22 == 021 || 22 == 901"},
+			{Bit: 52, When: "3 in 010000,011000 && 22 != 011"},
+			{Bit: 23, When: "55 present"},
+			{Bit: 45, When: "35 absent && 22 == 901"},
+		},
+	}
+	packager := DefaultPackager()
+	require.NoError(t, packager.AddRule(rule))
+	compiled := packager.RuleFor(MTIType("0200").ToMtiByte())
+
+	tests := []struct {
+		name       string
+		fields     map[int]string
+		violations []string
+	}{
+		{"valid", map[int]string{22: "051"}, nil},
+		{"prefix", map[int]string{22: "012"}, []string{"bit 14 conditional: missing when 22 ^= 01"}},
+		{"or", map[int]string{22: "901"}, []string{
+			"bit 35 conditional: missing when 22 == 021 || 22 == 901",
+			"bit 45 conditional: missing when 35 absent && 22 == 901",
+		}},
+		{"in and not equal", map[int]string{3: "010000", 22: "051"}, []string{
+			"bit 52 conditional: missing when 3 in 010000,011000 && 22 != 011",
+		}},
+		{"forbidden and not allowed", map[int]string{22: "051", 39: "00", 48: "X"}, []string{
+			"bit 39 forbidden: present",
+			"bit 48 not allowed: present",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := newTestRequest("000123")
+			for bit, value := range tt.fields {
+				msg.SetString(bit, value)
+			}
+
+			var violations []string
+			for _, v := range compiled.Validate(msg) {
+				violations = append(violations, v.Error())
+			}
+			assert.ElementsMatch(t, tt.violations, violations)
+		})
+	}
+
+	msg := newTestRequest("000123").Unset(2)
+	err := msg.ValidateRule(compiled)
+	assert.ErrorIs(t, err, ErrRuleViolation)
+	assert.EqualError(t, err, "bit 2 mandatory: missing")
+}
+
+func TestNewPackagerRules(t *testing.T) {
+	packager := newTestPackager(t, `"rules": [{"mti": "08x0", "mandatory": [70], "conditional": [{"bit": 48, "when": "70 == 101"}]}],`,
+		`"70": {"type": "n", "length": {"type": "FIXED", "max": 3}}`,
+	)
+	require.NotNil(t, packager.RuleFor(MTIType("0800").ToMtiByte()))
+
+	msg := NewMessage(packager)
+	msg.SetMtiString("0800")
+	msg.SetString(70, "101")
+	assert.EqualError(t, msg.ValidateMandatoryBits(), "bit 48 conditional: missing when 70 == 101")
+
+	_, err := NewPackager(strings.NewReader(`{"rules": [{"mti": "0800", "conditional": [{"bit": 48, "when": "70 is 101"}]}]}`))
+	assert.ErrorIs(t, err, ErrInvalidRule)
+}
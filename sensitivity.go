@@ -0,0 +1,141 @@
+package iso8583
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrInvalidSensitivity = errors.New("invalid sensitivity")
+	ErrHashKeyNotSet      = errors.New("sensitivity hash needs a key, see IsoPackager.SetHashKey")
+)
+
+// Sensitivity is how a field is redacted by Redact, MarshalJSON, Dump and the ValidationReport
+type Sensitivity string
+
+const (
+	SensitivityNone        Sensitivity = "none"         // shown as is
+	SensitivityMaskMiddle  Sensitivity = "mask-middle"  // first and last quarter kept, the middle masked
+	SensitivityFirst6Last4 Sensitivity = "first6-last4" // first 6 and last 4 characters kept, for the PAN
+	SensitivityMask        Sensitivity = "mask"         // every character masked, the length is kept
+	SensitivityHash        Sensitivity = "hash"         // HMAC-SHA-256 of the value in hex, to correlate without the value
+	SensitivityDrop        Sensitivity = "drop"         // left out
+)
+
+// defaultSensitivities apply to the fields without a sensitivity:
+// PAN, track 1, 2 and 3, PIN block and ICC data
+var defaultSensitivities = map[int]Sensitivity{
+	2:  SensitivityFirst6Last4,
+	35: SensitivityMask, // the end of track 2 is the service code and discretionary data
+	36: SensitivityMask,
+	45: SensitivityMask,
+	52: SensitivityMask,
+	55: SensitivityMask,
+}
+
+const maskChar = '*'
+
+// UnmarshalJSON Implement json.Unmarshaler
+func (s *Sensitivity) UnmarshalJSON(data []byte) error {
+	var v string
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	sensitivity := Sensitivity(strings.ToLower(v))
+	if !sensitivity.isValid() {
+		return ErrInvalidSensitivity
+	}
+	*s = sensitivity
+	return nil
+}
+
+func (s Sensitivity) isValid() bool {
+	switch s {
+	case SensitivityNone, SensitivityMaskMiddle, SensitivityFirst6Last4, SensitivityMask, SensitivityHash, SensitivityDrop:
+		return true
+	default:
+		return false
+	}
+}
+
+// Redact returns the redacted value, false when the value is dropped. The key is used
+// by SensitivityHash only, without a key the value is masked and ErrHashKeyNotSet returned.
+func (s Sensitivity) Redact(value string, key []byte) (string, bool, error) {
+	switch s {
+	case SensitivityMaskMiddle:
+		keep := len(value) / 4
+		return value[:keep] + strings.Repeat(string(maskChar), len(value)-2*keep) + value[len(value)-keep:], true, nil
+	case SensitivityFirst6Last4:
+		if len(value) <= 10 {
+			return strings.Repeat(string(maskChar), len(value)), true, nil
+		}
+		return value[:6] + strings.Repeat(string(maskChar), len(value)-10) + value[len(value)-4:], true, nil
+	case SensitivityMask:
+		return strings.Repeat(string(maskChar), len(value)), true, nil
+	case SensitivityHash:
+		if len(key) == 0 {
+			return strings.Repeat(string(maskChar), len(value)), true, ErrHashKeyNotSet
+		}
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(value))
+		return hex.EncodeToString(mac.Sum(nil)), true, nil
+	case SensitivityDrop:
+		return "", false, nil
+	default:
+		return value, true, nil
+	}
+}
+
+// sensitivity returns the sensitivity of the bit, the default one when
+// the packager does not configure the bit
+func (m *Message) sensitivity(bit int) Sensitivity {
+	if m.packager != nil && bit >= 0 && bit <= MaxBitNumber {
+		if s := m.packager.IsoPackagerConfig[bit].Sensitivity; s != "" {
+			return s
+		}
+	}
+	return defaultSensitivities[bit]
+}
+
+// SetHashKey sets the HMAC key of the fields with SensitivityHash, keep it secret
+// so the hashes cannot be brute-forced. Set it before the packager is shared.
+func (p *IsoPackager) SetHashKey(key []byte) {
+	p.hashKey = bytes.Clone(key)
+}
+
+// redactedValue returns the redacted value of the bit, false when it is dropped
+func (m *Message) redactedValue(bit int) (string, bool, error) {
+	var key []byte
+	if m.packager != nil {
+		key = m.packager.hashKey
+	}
+	value, ok, err := m.sensitivity(bit).Redact(m.GetString(bit), key)
+	if err != nil {
+		return value, ok, fmt.Errorf("bit %d: %w", bit, err)
+	}
+	return value, ok, nil
+}
+
+// Redact returns a copy of the message with every sensitive field redacted
+// and the dropped fields removed, e.g. to store or log it
+func (m *Message) Redact() (*Message, error) {
+	msg := NewMessage(m.packager)
+	msg.MTI = m.MTI
+	msg.header = m.header
+	for _, bit := range m.sortedBits() {
+		value, ok, err := m.redactedValue(bit)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			msg.SetString(bit, value)
+		}
+	}
+	return msg, nil
+}
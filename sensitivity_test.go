@@ -0,0 +1,93 @@
+package iso8583
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSensitivityRedact(t *testing.T) {
+	tests := []struct {
+		sensitivity Sensitivity
+		value       string
+		want        string
+		kept        bool
+	}{
+		{SensitivityNone, "4111111111111111", "4111111111111111", true},
+		{SensitivityMaskMiddle, "12345678", "12****78", true},
+		{SensitivityFirst6Last4, "4111111111111111", "411111******1111", true},
+		{SensitivityFirst6Last4, "4111111111", "**********", true},
+		{SensitivityMask, "1234", "****", true},
+		{SensitivityHash, "", "5d5d139563c95b5967b9bd9a8c9b233a9dedb45072794cd232dc1b74832607d0", true},
+		{SensitivityDrop, "1234", "", false},
+	}
+	for _, tt := range tests {
+		got, kept, err := tt.sensitivity.Redact(tt.value, []byte("key"))
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, "%s %q", tt.sensitivity, tt.value)
+		assert.Equal(t, tt.kept, kept, "%s %q", tt.sensitivity, tt.value)
+	}
+}
+
+func TestSensitivityUnmarshalJSON(t *testing.T) {
+	var s Sensitivity
+	require.NoError(t, json.Unmarshal([]byte(`"First6-Last4"`), &s))
+	assert.Equal(t, SensitivityFirst6Last4, s)
+	assert.ErrorIs(t, json.Unmarshal([]byte(`"partial"`), &s), ErrInvalidSensitivity)
+}
+
+func TestDefaultSensitivityMasksTrack2(t *testing.T) {
+	const track2 = "4111111111111111=25121010000012300000"
+
+	for name, packager := range map[string]*IsoPackager{
+		"go preset":   DefaultPackager(),
+		"json preset": newTestPackager(t, "", `"35": {"type": "z", "length": {"type": "LLVAR", "max": 37}}`),
+	} {
+		t.Run(name, func(t *testing.T) {
+			msg := NewMessage(packager)
+			msg.SetMtiString("0200")
+			msg.SetString(35, track2)
+
+			redacted, err := msg.Redact()
+			require.NoError(t, err)
+			assert.Equal(t, strings.Repeat("*", len(track2)), redacted.GetString(35))
+
+			b, err := json.Marshal(msg)
+			require.NoError(t, err)
+			assert.NotContains(t, string(b), "1230000")
+			assert.NotContains(t, msg.String(), "1230000")
+		})
+	}
+}
+
+func TestSensitivityHashNeedsKey(t *testing.T) {
+	packager := newTestPackager(t, "", `"2": {"type": "n", "length": {"type": "LLVAR", "max": 19}, "sensitivity": "hash"}`)
+	msg := NewMessage(packager)
+	msg.SetMtiString("0200")
+	msg.SetString(2, "4111111111111111")
+
+	_, err := msg.Redact()
+	assert.ErrorIs(t, err, ErrHashKeyNotSet)
+	_, err = json.Marshal(msg)
+	assert.ErrorIs(t, err, ErrHashKeyNotSet)
+	assert.ErrorIs(t, msg.Dump(&strings.Builder{}), ErrHashKeyNotSet)
+	assert.Empty(t, msg.String())
+	value, _, _ := msg.redactedValue(2)
+	assert.Equal(t, strings.Repeat("*", 16), value, "the value is masked without a key")
+
+	packager.SetHashKey([]byte("secret"))
+	redacted, err := msg.Redact()
+	require.NoError(t, err)
+	hashed := redacted.GetString(2)
+	assert.Len(t, hashed, 64)
+	assert.Contains(t, msg.String(), hashed)
+
+	other := newTestPackager(t, "", `"2": {"type": "n", "length": {"type": "LLVAR", "max": 19}, "sensitivity": "hash"}`)
+	other.SetHashKey([]byte("other"))
+	value, _, err = NewMessage(other).SetString(2, "4111111111111111").redactedValue(2)
+	require.NoError(t, err)
+	assert.NotEqual(t, hashed, value, "the hash depends on the key")
+}
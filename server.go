@@ -0,0 +1,275 @@
+package iso8583
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pentaly7/iso8583/framing"
+)
+
+var (
+	ErrServerClosed = errors.New("server closed")
+	ErrNoHandler    = errors.New("no handler for mti")
+)
+
+// Handler handles an incoming message and returns the response to write back,
+// a nil response writes nothing
+type Handler interface {
+	ServeISO(ctx context.Context, msg *Message) (*Message, error)
+}
+
+// HandlerFunc is an adapter to use a function as a Handler
+type HandlerFunc func(ctx context.Context, msg *Message) (*Message, error)
+
+func (f HandlerFunc) ServeISO(ctx context.Context, msg *Message) (*Message, error) {
+	return f(ctx, msg)
+}
+
+// Server accepts connections, unpacks the incoming messages and dispatches
+// them to the handler registered for their MTI
+type Server struct {
+	// NotFound handles messages without a handler for their MTI, nil reports ErrNoHandler to OnError
+	NotFound Handler
+	// OnError is called for connection, unpack, handler and pack errors
+	OnError func(err error)
+
+	packager *IsoPackager
+	codec    *framing.Codec
+
+	mu        sync.RWMutex
+	handlers  map[MTITypeByte]Handler
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+
+	connWG     sync.WaitGroup
+	inShutdown atomic.Bool
+	ctx        context.Context
+	cancel     context.CancelFunc
+}
+
+// NewServer creates a server reading frames with codec and unpacking them with packager
+func NewServer(packager *IsoPackager, codec *framing.Codec) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Server{
+		packager:  packager,
+		codec:     codec,
+		handlers:  make(map[MTITypeByte]Handler),
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Handle registers the handler for the MTI
+func (s *Server) Handle(mti MTIType, h Handler) {
+	s.mu.Lock()
+	s.handlers[mti.ToMtiByte()] = h
+	s.mu.Unlock()
+}
+
+// HandleFunc registers the handler function for the MTI
+func (s *Server) HandleFunc(mti MTIType, f func(ctx context.Context, msg *Message) (*Message, error)) {
+	s.Handle(mti, HandlerFunc(f))
+}
+
+// ListenAndServe listens on the TCP address and serves the connections
+func (s *Server) ListenAndServe(address string) error {
+	l, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve accepts connections on l until the server is shut down,
+// it always returns a non-nil error, ErrServerClosed after Shutdown.
+// Temporary accept errors such as running out of file descriptors are reported
+// to OnError and retried with a backoff of 5ms up to 1s, other errors are returned.
+func (s *Server) Serve(l net.Listener) error {
+	if !s.trackListener(l, true) {
+		_ = l.Close()
+		return ErrServerClosed
+	}
+	defer s.trackListener(l, false)
+
+	var delay time.Duration
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if s.inShutdown.Load() {
+				return ErrServerClosed
+			}
+			if !isTemporary(err) {
+				return err
+			}
+
+			delay = min(max(delay*2, 5*time.Millisecond), time.Second)
+			s.reportError(fmt.Errorf("accept: %w, retrying in %v", err, delay))
+			time.Sleep(delay)
+			continue
+		}
+		delay = 0
+
+		if !s.trackConn(conn, true) {
+			_ = conn.Close()
+			return ErrServerClosed
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// Shutdown stops accepting connections and reading messages, then waits for
+// the in-flight handlers to finish and their responses to be written.
+// When ctx is done first the connections are closed and ctx.Err() is returned.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.inShutdown.Store(true)
+
+	s.mu.Lock()
+	for l := range s.listeners {
+		_ = l.Close()
+	}
+	// unblock the readers, the connections are closed once drained
+	for conn := range s.conns {
+		_ = conn.SetReadDeadline(time.Now())
+	}
+	s.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		s.connWG.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		s.mu.Lock()
+		for conn := range s.conns {
+			_ = conn.Close()
+		}
+		s.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	var (
+		writeMu  sync.Mutex
+		inFlight sync.WaitGroup
+	)
+
+	defer func() {
+		inFlight.Wait()
+		_ = conn.Close()
+		s.trackConn(conn, false)
+	}()
+
+	for {
+		frame, err := s.codec.ReadFrame(conn)
+		if err != nil {
+			if !s.inShutdown.Load() {
+				s.reportError(err)
+			}
+			return
+		}
+
+		msg := NewMessage(s.packager)
+		if err = msg.Unpack(frame); err != nil {
+			s.reportError(err)
+			continue
+		}
+
+		inFlight.Add(1)
+		go func() {
+			defer inFlight.Done()
+
+			res, err := s.handler(msg).ServeISO(s.ctx, msg)
+			if err != nil {
+				s.reportError(fmt.Errorf("handling %s: %w", msg.MTI, err))
+				return
+			}
+			if res == nil {
+				return
+			}
+
+			b, err := res.PackISO()
+			if err != nil {
+				s.reportError(fmt.Errorf("packing response %s: %w", res.MTI, err))
+				return
+			}
+
+			writeMu.Lock()
+			defer writeMu.Unlock()
+			if err = s.codec.WriteFrame(conn, b); err != nil {
+				s.reportError(err)
+			}
+		}()
+	}
+}
+
+// handler returns the handler registered for the message MTI
+func (s *Server) handler(msg *Message) Handler {
+	s.mu.RLock()
+	h, ok := s.handlers[msg.MTI]
+	s.mu.RUnlock()
+	if ok {
+		return h
+	}
+	if s.NotFound != nil {
+		return s.NotFound
+	}
+	return HandlerFunc(func(ctx context.Context, msg *Message) (*Message, error) {
+		return nil, fmt.Errorf("%w %s", ErrNoHandler, msg.MTI)
+	})
+}
+
+// isTemporary reports whether the accept error is temporary, like net/http does
+func isTemporary(err error) bool {
+	var temporary interface{ Temporary() bool }
+	return errors.As(err, &temporary) && temporary.Temporary()
+}
+
+func (s *Server) reportError(err error) {
+	if s.OnError != nil {
+		s.OnError(err)
+	}
+}
+
+func (s *Server) trackListener(l net.Listener, add bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if add {
+		if s.inShutdown.Load() {
+			return false
+		}
+		s.listeners[l] = struct{}{}
+	} else {
+		delete(s.listeners, l)
+	}
+	return true
+}
+
+func (s *Server) trackConn(conn net.Conn, add bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if add {
+		if s.inShutdown.Load() {
+			return false
+		}
+		s.conns[conn] = struct{}{}
+		s.connWG.Add(1)
+	} else {
+		delete(s.conns, conn)
+		s.connWG.Done()
+	}
+	return true
+}
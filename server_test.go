@@ -0,0 +1,118 @@
+package iso8583
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/pentaly7/iso8583/framing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyListener returns the errors before accepting connections
+type flakyListener struct {
+	net.Listener
+	errs    chan error
+	accepts atomic.Int32
+}
+
+func (l *flakyListener) Accept() (net.Conn, error) {
+	l.accepts.Add(1)
+	select {
+	case err := <-l.errs:
+		return nil, err
+	default:
+		return l.Listener.Accept()
+	}
+}
+
+func startTestServer(t *testing.T, s *Server, l net.Listener) chan error {
+	t.Helper()
+	served := make(chan error, 1)
+	go func() {
+		served <- s.Serve(l)
+	}()
+	return served
+}
+
+func TestServerRoutesByMTI(t *testing.T) {
+	codec := framing.NewCodec(framing.BinaryHeader(2))
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := NewServer(DefaultPackager(), codec)
+	s.HandleFunc(MTIFinancialRequest, func(ctx context.Context, msg *Message) (*Message, error) {
+		return CreateResponseISO(msg, ResponseCodeApproved)
+	})
+	served := startTestServer(t, s, l)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	client, err := Dial(ctx, "tcp", l.Addr().String(), DefaultPackager(), codec)
+	require.NoError(t, err)
+	defer client.Close()
+
+	res, err := client.Send(ctx, newTestRequest("000001"))
+	require.NoError(t, err)
+	assert.Equal(t, "0210", res.MTI.String())
+	assert.Equal(t, ResponseCodeApproved, res.GetString(39))
+
+	require.NoError(t, s.Shutdown(ctx))
+	assert.ErrorIs(t, <-served, ErrServerClosed)
+}
+
+func TestServerRetriesTemporaryAcceptErrors(t *testing.T) {
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	emfile := &net.OpError{Op: "accept", Net: "tcp", Err: os.NewSyscallError("accept", syscall.EMFILE)}
+	l := &flakyListener{Listener: inner, errs: make(chan error, 3)}
+	for range 3 {
+		l.errs <- emfile
+	}
+
+	var reported atomic.Int32
+	s := NewServer(DefaultPackager(), framing.NewCodec(framing.BinaryHeader(2)))
+	s.OnError = func(err error) { reported.Add(1) }
+	served := startTestServer(t, s, l)
+
+	conn, err := net.Dial("tcp", inner.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.Eventually(t, func() bool { return l.accepts.Load() >= 4 }, time.Second, 5*time.Millisecond)
+	assert.EqualValues(t, 3, reported.Load())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, s.Shutdown(ctx))
+	assert.ErrorIs(t, <-served, ErrServerClosed)
+}
+
+func TestServerReturnsPermanentAcceptError(t *testing.T) {
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer inner.Close()
+
+	permanent := errors.New("listener broken")
+	l := &flakyListener{Listener: inner, errs: make(chan error, 1)}
+	l.errs <- permanent
+
+	s := NewServer(DefaultPackager(), framing.NewCodec(framing.BinaryHeader(2)))
+	assert.ErrorIs(t, s.Serve(l), permanent)
+}
+
+func TestServerServeAfterShutdown(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := NewServer(DefaultPackager(), framing.NewCodec(framing.BinaryHeader(2)))
+	require.NoError(t, s.Shutdown(context.Background()))
+	assert.ErrorIs(t, s.Serve(l), ErrServerClosed)
+}
@@ -0,0 +1,321 @@
+package iso8583
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrSubfieldNotFound      = errors.New("subfield not found")
+	ErrInvalidSubfieldLength = errors.New("invalid subfield length")
+	ErrInvalidSubfields      = errors.New("invalid subfields config")
+)
+
+// SubfieldFormat is the layout of the subfields of a field
+type SubfieldFormat string
+
+const (
+	SubfieldFormatFixed SubfieldFormat = "FIXED" // each subfield at a fixed position with a fixed length
+	SubfieldFormatTLV   SubfieldFormat = "TLV"   // tag, decimal length and value of each present subfield
+)
+
+// UnmarshalJSON Implement json.Unmarshaler
+func (f *SubfieldFormat) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	format := SubfieldFormat(strings.ToUpper(s))
+	switch format {
+	case SubfieldFormatFixed, SubfieldFormatTLV:
+		*f = format
+	default:
+		return ErrInvalidSubfields
+	}
+	return nil
+}
+
+const (
+	defaultSubfieldTagLength    = 2
+	defaultSubfieldLengthLength = 3
+)
+
+// Subfields describes the internal structure of a field, e.g. DE 3 or DE 90
+//
+//	"subfields": {"format": "FIXED", "fields": [
+//	  {"name": "transactionType", "type": "n", "length": 2},
+//	  {"name": "fromAccount", "type": "n", "length": 2},
+//	  {"name": "toAccount", "type": "n", "length": 2}
+//	]}
+type Subfields struct {
+	Format       SubfieldFormat   `json:"format"`       // "FIXED" (default) or "TLV"
+	TagLength    int              `json:"tagLength"`    // TLV tag characters, default 2
+	LengthLength int              `json:"lengthLength"` // TLV length digits, default 3
+	Fields       []SubfieldConfig `json:"fields"`
+}
+
+// SubfieldConfig is a single subfield, Length is the exact length of a fixed
+// subfield and the max length of a TLV subfield (0 for no max)
+type SubfieldConfig struct {
+	Name   string  `json:"name"`
+	Type   BitType `json:"type"`
+	Length int     `json:"length"`
+	Tag    string  `json:"tag"` // TLV only
+
+	offset int // fixed only, pre-computed position in the parent field
+}
+
+// NewSubfieldsFixed creates fixed position subfields in the order of fields
+func NewSubfieldsFixed(fields ...SubfieldConfig) *Subfields {
+	return &Subfields{Format: SubfieldFormatFixed, Fields: fields}
+}
+
+// compile validates the subfields of the bit and pre-computes the fixed positions,
+// it returns a copy so packagers never share subfields
+func (s *Subfields) compile(bit, maxLength int) (*Subfields, error) {
+	c := *s
+	c.Fields = slices.Clone(s.Fields)
+
+	switch c.Format {
+	case "":
+		c.Format = SubfieldFormatFixed
+	case SubfieldFormatFixed, SubfieldFormatTLV:
+	default:
+		return nil, fmt.Errorf("%w: format %q for bit %d", ErrInvalidSubfields, c.Format, bit)
+	}
+	if c.TagLength == 0 {
+		c.TagLength = defaultSubfieldTagLength
+	}
+	if c.LengthLength == 0 {
+		c.LengthLength = defaultSubfieldLengthLength
+	}
+
+	offset := 0
+	for i := range c.Fields {
+		f := &c.Fields[i]
+		if f.Name == "" || slices.IndexFunc(c.Fields[:i], func(o SubfieldConfig) bool { return o.Name == f.Name }) >= 0 {
+			return nil, fmt.Errorf("%w: missing or duplicate name %q for bit %d", ErrInvalidSubfields, f.Name, bit)
+		}
+		if c.Format == SubfieldFormatTLV {
+			if len(f.Tag) != c.TagLength {
+				return nil, fmt.Errorf("%w: tag %q of %s for bit %d", ErrInvalidSubfields, f.Tag, f.Name, bit)
+			}
+			continue
+		}
+		if f.Length <= 0 {
+			return nil, fmt.Errorf("%w: length of %s for bit %d", ErrInvalidSubfields, f.Name, bit)
+		}
+		f.offset = offset
+		offset += f.Length
+	}
+
+	if c.Format == SubfieldFormatFixed && offset > maxLength {
+		return nil, fmt.Errorf("%w: subfields of bit %d take %d, max %d", ErrInvalidSubfields, bit, offset, maxLength)
+	}
+	return &c, nil
+}
+
+// field returns the subfield config with the name
+func (s *Subfields) field(name string) *SubfieldConfig {
+	for i := range s.Fields {
+		if s.Fields[i].Name == name {
+			return &s.Fields[i]
+		}
+	}
+	return nil
+}
+
+// subfield returns the subfields config of the bit and the subfield with the name
+func (m *Message) subfield(bit int, name string) (*Subfields, *SubfieldConfig, error) {
+	if bit < 0 || bit > MaxBitNumber {
+		return nil, nil, fmt.Errorf("%w: %d", ErrInvalidBitNumber, bit)
+	}
+	subfields := m.packager.IsoPackagerConfig[bit].Subfields
+	if subfields == nil {
+		return nil, nil, fmt.Errorf("%w: bit %d has no subfields", ErrSubfieldNotFound, bit)
+	}
+	f := subfields.field(name)
+	if f == nil {
+		return nil, nil, fmt.Errorf("%w: %d.%s", ErrSubfieldNotFound, bit, name)
+	}
+	return subfields, f, nil
+}
+
+// GetSubfield returns the value of the named subfield of the bit,
+// an empty string when the bit or the TLV subfield is not set
+func (m *Message) GetSubfield(bit int, name string) (string, error) {
+	subfields, f, err := m.subfield(bit, name)
+	if err != nil || !m.HasBit(bit) {
+		return "", err
+	}
+
+	parent := m.GetString(bit)
+	if subfields.Format == SubfieldFormatFixed {
+		if len(parent) < f.offset+f.Length {
+			return "", fmt.Errorf("%w: bit %d is too short for %s", ErrInvalidSubfieldLength, bit, name)
+		}
+		return parent[f.offset : f.offset+f.Length], nil
+	}
+
+	start, end, _, err := subfields.findTLV(parent, f.Tag)
+	if err != nil || start < 0 {
+		return "", err
+	}
+	return parent[start:end], nil
+}
+
+// SetSubfield sets the named subfield and rewrites the bit.
+// Fixed subfields shorter than their length are padded, numeric ones with leading
+// zeros and the others with trailing spaces. A missing bit is created, a fixed length
+// bit at its full length.
+func (m *Message) SetSubfield(bit int, name, value string) error {
+	subfields, f, err := m.subfield(bit, name)
+	if err != nil {
+		return err
+	}
+	if f.Length > 0 && len(value) > f.Length {
+		return fmt.Errorf("%w: %d.%s max %d, got %d", ErrInvalidSubfieldLength, bit, name, f.Length, len(value))
+	}
+
+	parent := m.GetString(bit)
+	var b []byte
+	if subfields.Format == SubfieldFormatFixed {
+		b = subfields.setFixed(parent, f, value, m.packager.IsoPackagerConfig[bit])
+	} else if b, err = subfields.setTLV(parent, f, value); err != nil {
+		return err
+	}
+
+	m.SetByte(bit, b)
+	return nil
+}
+
+// setFixed returns a copy of the parent with the subfield replaced. A short parent is padded
+// to the fixed length of the bit, or to the end of the subfield, with zeros under numeric
+// subfields and spaces elsewhere, so a partly present subfield keeps its characters.
+func (s *Subfields) setFixed(parent string, f *SubfieldConfig, value string, config BitConfig) []byte {
+	size := max(len(parent), f.offset+f.Length)
+	if config.Length.Type == LengthTypeFixed {
+		size = max(size, config.Length.Max)
+	}
+	b := make([]byte, size)
+	copy(b, parent)
+	for i := len(parent); i < size; i++ {
+		b[i] = s.filler(i, config.Type)
+	}
+	copy(b[f.offset:f.offset+f.Length], padSubfield(f, value))
+	return b
+}
+
+// filler returns the padding character at the position of a fixed parent,
+// positions after the subfields are padded as the parent type
+func (s *Subfields) filler(pos int, parentType BitType) byte {
+	typ := parentType
+	for i := range s.Fields {
+		if o := &s.Fields[i]; pos >= o.offset && pos < o.offset+o.Length {
+			typ = o.Type
+			break
+		}
+	}
+	if typ == BitTypeN {
+		return '0'
+	}
+	return ' '
+}
+
+// setTLV returns a copy of the parent with the subfield replaced or appended
+func (s *Subfields) setTLV(parent string, f *SubfieldConfig, value string) ([]byte, error) {
+	if len(value) >= pow10(s.LengthLength) {
+		return nil, fmt.Errorf("%w: %s too long for %d length digits", ErrInvalidSubfieldLength, f.Name, s.LengthLength)
+	}
+	start, end, tagStart, err := s.findTLV(parent, f.Tag)
+	if err != nil {
+		return nil, err
+	}
+
+	element := make([]byte, 0, s.TagLength+s.LengthLength+len(value))
+	element = append(element, f.Tag...)
+	element = append(element, fmt.Sprintf("%0*d", s.LengthLength, len(value))...)
+	element = append(element, value...)
+
+	if start < 0 {
+		return append([]byte(parent), element...), nil
+	}
+	b := make([]byte, 0, len(parent)-(end-tagStart)+len(element))
+	b = append(b, parent[:tagStart]...)
+	b = append(b, element...)
+	return append(b, parent[end:]...), nil
+}
+
+// findTLV returns the value position and the tag position of the tag in the parent,
+// start is -1 when the tag is not present
+func (s *Subfields) findTLV(parent, tag string) (start, end, tagStart int, err error) {
+	for pos := 0; pos < len(parent); {
+		header := pos + s.TagLength + s.LengthLength
+		if header > len(parent) {
+			return -1, -1, -1, fmt.Errorf("%w: truncated tag at %d", ErrInvalidSubfieldLength, pos)
+		}
+		length, errConv := strconv.Atoi(parent[pos+s.TagLength : header])
+		if errConv != nil || length < 0 || header+length > len(parent) {
+			return -1, -1, -1, fmt.Errorf("%w: invalid length at %d", ErrInvalidSubfieldLength, pos)
+		}
+		if parent[pos:pos+s.TagLength] == tag {
+			return header, header + length, pos, nil
+		}
+		pos = header + length
+	}
+	return -1, -1, -1, nil
+}
+
+// validate checks the charset of the present subfields of the parent value
+func (s *Subfields) validate(bit int, parent string) *RuleViolation {
+	for i := range s.Fields {
+		f := &s.Fields[i]
+		if f.Type == "" {
+			continue
+		}
+		var value string
+		if s.Format == SubfieldFormatFixed {
+			if len(parent) < f.offset+f.Length {
+				return &RuleViolation{Bit: bit, Rule: RuleLength, Detail: fmt.Sprintf("too short for subfield %s", f.Name)}
+			}
+			value = parent[f.offset : f.offset+f.Length]
+		} else {
+			start, end, _, err := s.findTLV(parent, f.Tag)
+			if err != nil {
+				return &RuleViolation{Bit: bit, Rule: RuleLength, Detail: err.Error()}
+			}
+			if start < 0 {
+				continue
+			}
+			value = parent[start:end]
+		}
+		if !validCharset(f.Type, []byte(value)) {
+			return &RuleViolation{Bit: bit, Rule: RuleCharset, Detail: fmt.Sprintf("subfield %s not %s", f.Name, f.Type)}
+		}
+	}
+	return nil
+}
+
+// padSubfield pads the value to the fixed length of the subfield
+func padSubfield(f *SubfieldConfig, value string) string {
+	pad := f.Length - len(value)
+	if pad <= 0 {
+		return value
+	}
+	if f.Type == BitTypeN {
+		return strings.Repeat("0", pad) + value
+	}
+	return value + strings.Repeat(" ", pad)
+}
+
+func pow10(n int) int {
+	p := 1
+	for i := 0; i < n; i++ {
+		p *= 10
+	}
+	return p
+}
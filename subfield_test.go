@@ -0,0 +1,133 @@
+package iso8583
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tlvSubfieldsField is DE 48 with TLV subfields of 2 character tags and 3 digit lengths
+const tlvSubfieldsField = `"48": {"type": "ans", "length": {"type": "LLLVAR", "max": 999}, "subfields": {"format": "tlv", "fields": [
+	{"name": "terminalType", "tag": "01", "type": "n", "length": 2},
+	{"name": "merchantName", "tag": "02", "type": "ans", "length": 20}
+]}}`
+
+func TestFixedSubfields(t *testing.T) {
+	msg := NewMessage(DefaultPackager())
+
+	require.NoError(t, msg.SetSubfield(3, "fromAccount", "10"))
+	assert.Equal(t, "001000", msg.GetString(3), "the missing subfields are zero filled")
+
+	require.NoError(t, msg.SetSubfield(3, "transactionType", "1"))
+	assert.Equal(t, "011000", msg.GetString(3), "numeric subfields are padded on the left")
+
+	value, err := msg.GetSubfield(3, "fromAccount")
+	require.NoError(t, err)
+	assert.Equal(t, "10", value)
+
+	err = msg.SetSubfield(3, "toAccount", "123")
+	assert.ErrorIs(t, err, ErrInvalidSubfieldLength)
+
+	_, err = msg.GetSubfield(3, "cardType")
+	assert.ErrorIs(t, err, ErrSubfieldNotFound)
+	_, err = msg.GetSubfield(4, "amount")
+	assert.ErrorIs(t, err, ErrSubfieldNotFound)
+
+	value, err = NewMessage(DefaultPackager()).GetSubfield(3, "toAccount")
+	require.NoError(t, err)
+	assert.Empty(t, value, "the bit is not set")
+
+	msg.SetString(3, "0110")
+	_, err = msg.GetSubfield(3, "toAccount")
+	assert.ErrorIs(t, err, ErrInvalidSubfieldLength)
+}
+
+func TestFixedSubfieldsPadParent(t *testing.T) {
+	msg := NewMessage(DefaultPackager())
+	msg.SetMtiString("0400")
+	require.NoError(t, msg.SetSubfield(90, "originalStan", "123"))
+	assert.Equal(t, "0000"+"000123"+strings.Repeat("0", 32), msg.GetString(90))
+	_, err := msg.PackISO()
+	require.NoError(t, err)
+
+	// the subfields cover 6 of the 10 characters of the bit
+	packager := newTestPackager(t, "", `"60": {"type": "ans", "length": {"type": "FIXED", "max": 10}, "subfields": {"fields": [
+		{"name": "terminalType", "type": "n", "length": 2},
+		{"name": "terminalName", "type": "ans", "length": 4}
+	]}}`)
+	msg = NewMessage(packager)
+	msg.SetMtiString("0200")
+	require.NoError(t, msg.SetSubfield(60, "terminalType", "7"))
+	assert.Equal(t, "07        ", msg.GetString(60))
+	_, err = msg.PackISO()
+	require.NoError(t, err)
+
+	msg.SetString(60, "07AB")
+	require.NoError(t, msg.SetSubfield(60, "terminalType", "08"))
+	assert.Equal(t, "08AB      ", msg.GetString(60), "the partly present subfield is kept")
+}
+
+func TestTLVSubfields(t *testing.T) {
+	packager := newTestPackager(t, "", tlvSubfieldsField)
+	msg := NewMessage(packager)
+
+	require.NoError(t, msg.SetSubfield(48, "merchantName", "SHOP"))
+	require.NoError(t, msg.SetSubfield(48, "terminalType", "07"))
+	assert.Equal(t, "02004SHOP0100207", msg.GetString(48))
+
+	require.NoError(t, msg.SetSubfield(48, "merchantName", "BIGGER SHOP"))
+	assert.Equal(t, "02011BIGGER SHOP0100207", msg.GetString(48), "the subfield is replaced in place")
+
+	value, err := msg.GetSubfield(48, "terminalType")
+	require.NoError(t, err)
+	assert.Equal(t, "07", value)
+
+	err = msg.SetSubfield(48, "merchantName", strings.Repeat("X", 21))
+	assert.ErrorIs(t, err, ErrInvalidSubfieldLength)
+}
+
+func TestTLVSubfieldsMalformed(t *testing.T) {
+	packager := newTestPackager(t, "", tlvSubfieldsField)
+
+	for _, parent := range []string{"02004SHO", "0200", "02X04SHOP"} {
+		msg := NewMessage(packager)
+		msg.SetString(48, parent)
+
+		_, err := msg.GetSubfield(48, "terminalType")
+		assert.ErrorIs(t, err, ErrInvalidSubfieldLength, parent)
+		assert.ErrorIs(t, msg.SetSubfield(48, "terminalType", "07"), ErrInvalidSubfieldLength, parent)
+
+		v := msg.validateBit(48)
+		if assert.NotNil(t, v, parent) {
+			assert.Equal(t, RuleLength, v.Rule)
+		}
+	}
+
+	msg := NewMessage(packager)
+	msg.SetString(48, "01002A7")
+	v := msg.validateBit(48)
+	require.NotNil(t, v)
+	assert.Equal(t, "subfield terminalType not n", v.Detail)
+}
+
+func TestSubfieldsConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		subfields string
+	}{
+		{"format", `{"format": "bitmap", "fields": [{"name": "a", "length": 1}]}`},
+		{"duplicate name", `{"fields": [{"name": "a", "length": 1}, {"name": "a", "length": 1}]}`},
+		{"missing length", `{"fields": [{"name": "a"}]}`},
+		{"longer than the field", `{"fields": [{"name": "a", "length": 4}, {"name": "b", "length": 3}]}`},
+		{"tlv tag length", `{"format": "tlv", "fields": [{"name": "a", "tag": "001"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := `{"packagerConfig": {"3": {"type": "n", "length": {"type": "FIXED", "max": 6}, "subfields": ` + tt.subfields + `}}}`
+			_, err := NewPackager(strings.NewReader(config))
+			assert.ErrorIs(t, err, ErrInvalidSubfields)
+		})
+	}
+}
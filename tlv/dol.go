@@ -0,0 +1,162 @@
+package tlv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DOLEntry is a tag and the length of its value in a Data Object List
+type DOLEntry struct {
+	Tag    uint32
+	Length int
+}
+
+// DOL is a Data Object List such as a PDOL (9F38), CDOL1 (8C), CDOL2 (8D) or DDOL (9F49):
+// tags and lengths without values. The card asks with it for the concatenated values
+// of the tags, see Build and Split.
+type DOL []DOLEntry
+
+// ParseDOL parses the tags and lengths of a DOL, e.g. the value of 9F38.
+// DOL lengths are a single byte below 0x80, the long form is rejected.
+func ParseDOL(data []byte) (DOL, error) {
+	var dol DOL
+	for i := 0; i < len(data); {
+		tag, next, err := readTag(data, i)
+		if err != nil {
+			return nil, err
+		}
+		if next >= len(data) {
+			return nil, fmt.Errorf("unexpected end while reading length of tag %X", tag)
+		}
+		if data[next]&0x80 != 0 {
+			return nil, fmt.Errorf("%w: tag %X has a long form dol length", ErrInvalidLength, tag)
+		}
+		dol = append(dol, DOLEntry{Tag: tag, Length: int(data[next])})
+		i = next + 1
+	}
+	return dol, nil
+}
+
+// maxDOLLength is the highest length of a DOL entry, a DOL length is a single byte
+// in the short form
+const maxDOLLength = 0x7F
+
+// Validate checks the tags of the DOL and that every length is 0 to 0x7F,
+// so the DOL packs as ParseDOL reads it
+func (d DOL) Validate() error {
+	for _, e := range d {
+		if err := ValidateTag(e.Tag); err != nil {
+			return err
+		}
+		if e.Length < 0 || e.Length > maxDOLLength {
+			return fmt.Errorf("%w: tag %X has a dol length of %d, expected 0 to %d",
+				ErrInvalidLength, e.Tag, e.Length, maxDOLLength)
+		}
+	}
+	return nil
+}
+
+// Pack encodes the tags and lengths of a valid DOL, see Validate
+func (d DOL) Pack() ([]byte, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	result := make([]byte, 0, len(d)*3)
+	for _, e := range d {
+		result = append(result, Uint32ToBytes(e.Tag)...)
+		result = append(result, byte(e.Length))
+	}
+	return result, nil
+}
+
+// Length returns the length of the values of the DOL
+func (d DOL) Length() int {
+	length := 0
+	for _, e := range d {
+		length += e.Length
+	}
+	return length
+}
+
+// Build returns the concatenated values of the DOL tags taken from the source,
+// looked up at its top level, following the EMV rules:
+//   - a tag missing from the source or constructed is filled with zeros
+//   - a shorter value is padded, numeric (n) with leading zeros, compressed numeric (cn)
+//     with trailing 0xFF and the other formats with trailing zeros
+//   - a longer value is truncated, numeric on the left and the other formats on the right
+//
+// The format of a tag is taken from the dictionary, unknown tags are binary.
+// The DOL must be valid, see Validate.
+func (d DOL) Build(source *Data) ([]byte, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	result := make([]byte, 0, d.Length())
+	for _, e := range d {
+		var value []byte
+		if k := source.find([]uint32{e.Tag}); k != nil && !IsConstructed(e.Tag) {
+			value = k.value
+		}
+		result = appendDOLValue(result, dictionary[e.Tag].Format, value, e.Length)
+	}
+	return result, nil
+}
+
+// appendDOLValue appends the value padded or truncated to the length
+func appendDOLValue(result []byte, format Format, value []byte, length int) []byte {
+	numeric := format == FormatNumeric
+	if value == nil {
+		numeric = false // zeros whatever the format
+	}
+
+	switch {
+	case len(value) > length && numeric:
+		return append(result, value[len(value)-length:]...)
+	case len(value) > length:
+		return append(result, value[:length]...)
+	}
+
+	var padding byte
+	if format == FormatCompressedNumeric && value != nil {
+		padding = 0xFF
+	}
+	pad := length - len(value)
+	if numeric {
+		result = append(result, make([]byte, pad)...)
+		return append(result, value...)
+	}
+	result = append(result, value...)
+	for ; pad > 0; pad-- {
+		result = append(result, padding)
+	}
+	return result
+}
+
+// Split splits the concatenated values of the DOL, e.g. the data sent by the terminal
+// in GET PROCESSING OPTIONS or GENERATE AC, into tags. The values are kept as they are,
+// constructed tags are not parsed. The DOL must be valid, see Validate.
+func (d DOL) Split(data []byte) (*Data, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	if len(data) != d.Length() {
+		return nil, fmt.Errorf("dol values of %d bytes, expected %d", len(data), d.Length())
+	}
+
+	result := &Data{list: make([]TagData, 0, len(d))}
+	i := 0
+	for _, e := range d {
+		result.list = append(result.list, TagData{tag: e.Tag, value: data[i : i+e.Length]})
+		i += e.Length
+	}
+	return result, nil
+}
+
+// String returns the DOL entries, e.g. "9F66 4, 9F02 6"
+func (d DOL) String() string {
+	entries := make([]string, len(d))
+	for i, e := range d {
+		entries[i] = fmt.Sprintf("%X %d", e.Tag, e.Length)
+	}
+	return strings.Join(entries, ", ")
+}
@@ -0,0 +1,111 @@
+package tlv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDOLRoundTrip(t *testing.T) {
+	// 9F66 4, 9F02 6, 5F2A 2, 9A 3, 5A 10
+	raw := []byte{0x9F, 0x66, 0x04, 0x9F, 0x02, 0x06, 0x5F, 0x2A, 0x02, 0x9A, 0x03, 0x5A, 0x0A}
+	dol, err := ParseDOL(raw)
+	require.NoError(t, err)
+	assert.Equal(t, "9F66 4, 9F02 6, 5F2A 2, 9A 3, 5A 10", dol.String())
+	packed, err := dol.Pack()
+	require.NoError(t, err)
+	assert.Equal(t, raw, packed)
+	assert.Equal(t, 25, dol.Length())
+
+	source := &Data{}
+	require.NoError(t, source.Append(0x9F02, []byte{0x10, 0x00}))
+	require.NoError(t, source.Append(0x5F2A, []byte{0x00, 0x08, 0x40}))
+	require.NoError(t, source.Append(0x5A, []byte{0x41, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x1F}))
+
+	values, err := dol.Build(source)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{
+		0x00, 0x00, 0x00, 0x00, // 9F66 missing
+		0x00, 0x00, 0x00, 0x00, 0x10, 0x00, // 9F02 n padded on the left
+		0x08, 0x40, // 5F2A n truncated on the left
+		0x00, 0x00, 0x00, // 9A missing
+		0x41, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x1F, 0xFF, 0xFF, // 5A cn padded with 0xFF
+	}, values)
+
+	split, err := dol.Split(values)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x08, 0x40}, split.GetBytes(0x5F2A))
+	assert.Equal(t, values, concatValues(split))
+
+	_, err = dol.Split(values[1:])
+	assert.Error(t, err)
+}
+
+func concatValues(data *Data) (result []byte) {
+	_ = data.Walk(func(_ []uint32, k *TagData) error {
+		result = append(result, k.Value()...)
+		return nil
+	})
+	return result
+}
+
+func TestParseDOLMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"long form length of 8 bytes", []byte{0x9A, 0x88, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}},
+		{"long form length", []byte{0x9F, 0x02, 0x81, 0x06}},
+		{"missing length", []byte{0x9F, 0x02}},
+		{"truncated tag", []byte{0x9F}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dol, err := ParseDOL(tt.data)
+			assert.Error(t, err)
+			assert.Nil(t, dol)
+		})
+	}
+}
+
+func TestDOLLengthBoundary(t *testing.T) {
+	dol := DOL{{Tag: 0xDF01, Length: 0}, {Tag: 0x9F4E, Length: 0x7F}}
+	packed, err := dol.Pack()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xDF, 0x01, 0x00, 0x9F, 0x4E, 0x7F}, packed)
+
+	parsed, err := ParseDOL(packed)
+	require.NoError(t, err)
+	assert.Equal(t, dol, parsed)
+
+	values, err := parsed.Build(&Data{})
+	require.NoError(t, err)
+	assert.Len(t, values, 0x7F)
+}
+
+func TestDOLInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		dol  DOL
+	}{
+		{"negative length", DOL{{Tag: 0x9A, Length: -1}, {Tag: 0x9F02, Length: 6}}},
+		{"long form length", DOL{{Tag: 0x9F4E, Length: 0x80}}},
+		{"invalid tag", DOL{{Tag: 0, Length: 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, tt.dol.Validate())
+			packed, err := tt.dol.Pack()
+			assert.Error(t, err)
+			assert.Nil(t, packed)
+			_, err = tt.dol.Build(&Data{})
+			assert.Error(t, err)
+			_, err = tt.dol.Split(make([]byte, 6))
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := DOL{{Tag: 0x9F4E, Length: 0x80}}.Pack()
+	assert.ErrorIs(t, err, ErrInvalidLength)
+}
@@ -0,0 +1,190 @@
+package tlv
+
+// Format is the EMV data element format of a tag value
+type Format string
+
+const (
+	FormatNumeric             Format = "n"   // BCD digits right justified, padded with leading zeros
+	FormatCompressedNumeric   Format = "cn"  // BCD digits left justified, padded with trailing 0xF
+	FormatBinary              Format = "b"   // binary, shown in hex
+	FormatAlpha               Format = "a"   // letters
+	FormatAlphaNumeric        Format = "an"  // letters, digits and spaces
+	FormatAlphaNumericSpecial Format = "ans" // printable characters
+)
+
+// Class is the class of a tag, bits 8 and 7 of its first byte
+type Class byte
+
+const (
+	ClassUniversal       Class = 0x00
+	ClassApplication     Class = 0x40
+	ClassContextSpecific Class = 0x80
+	ClassPrivate         Class = 0xC0
+)
+
+func (c Class) String() string {
+	switch c {
+	case ClassUniversal:
+		return "universal"
+	case ClassApplication:
+		return "application"
+	case ClassContextSpecific:
+		return "context-specific"
+	default:
+		return "private"
+	}
+}
+
+// ClassOf returns the class of the tag
+func ClassOf(tag uint32) Class {
+	return Class(firstTagByte(tag) & 0xC0)
+}
+
+// TagInfo describes a tag of the dictionary, the lengths are in bytes of the value
+type TagInfo struct {
+	Tag       uint32
+	Name      string
+	Class     Class
+	Format    Format
+	MinLength int
+	MaxLength int
+	Sensitive bool // card holder data, masked by Dump
+}
+
+// dictionary holds the EMV Book 3 and contactless tags, see LookupTag
+var dictionary = make(map[uint32]TagInfo)
+
+// LookupTag returns the dictionary entry of the tag
+func LookupTag(tag uint32) (TagInfo, bool) {
+	info, ok := dictionary[tag]
+	return info, ok
+}
+
+// TagName returns the name of the tag, empty when the tag is not in the dictionary
+func TagName(tag uint32) string {
+	return dictionary[tag].Name
+}
+
+// RegisterTag adds or replaces a dictionary entry, e.g. for scheme proprietary tags.
+// The class is taken from the tag. It is not safe to call concurrently with the lookups,
+// register the tags at init.
+func RegisterTag(info TagInfo) error {
+	if err := ValidateTag(info.Tag); err != nil {
+		return err
+	}
+	info.Class = ClassOf(info.Tag)
+	dictionary[info.Tag] = info
+	return nil
+}
+
+func init() {
+	for _, info := range emvTags {
+		if err := RegisterTag(info); err != nil {
+			panic(err)
+		}
+	}
+}
+
+var emvTags = []TagInfo{
+	{Tag: 0x42, Name: "Issuer Identification Number (IIN)", Format: FormatNumeric, MinLength: 3, MaxLength: 3},
+	{Tag: 0x4F, Name: "Application Dedicated File (ADF) Name", Format: FormatBinary, MinLength: 5, MaxLength: 16},
+	{Tag: 0x50, Name: "Application Label", Format: FormatAlphaNumericSpecial, MinLength: 1, MaxLength: 16},
+	{Tag: 0x56, Name: "Track 1 Data", Format: FormatAlphaNumericSpecial, MinLength: 0, MaxLength: 76, Sensitive: true},
+	{Tag: 0x57, Name: "Track 2 Equivalent Data", Format: FormatBinary, MinLength: 0, MaxLength: 19, Sensitive: true},
+	{Tag: 0x5A, Name: "Application Primary Account Number (PAN)", Format: FormatCompressedNumeric, MinLength: 0, MaxLength: 10, Sensitive: true},
+	{Tag: 0x5F20, Name: "Cardholder Name", Format: FormatAlphaNumericSpecial, MinLength: 2, MaxLength: 26, Sensitive: true},
+	{Tag: 0x5F24, Name: "Application Expiration Date", Format: FormatNumeric, MinLength: 3, MaxLength: 3},
+	{Tag: 0x5F25, Name: "Application Effective Date", Format: FormatNumeric, MinLength: 3, MaxLength: 3},
+	{Tag: 0x5F28, Name: "Issuer Country Code", Format: FormatNumeric, MinLength: 2, MaxLength: 2},
+	{Tag: 0x5F2A, Name: "Transaction Currency Code", Format: FormatNumeric, MinLength: 2, MaxLength: 2},
+	{Tag: 0x5F2D, Name: "Language Preference", Format: FormatAlphaNumeric, MinLength: 2, MaxLength: 8},
+	{Tag: 0x5F30, Name: "Service Code", Format: FormatNumeric, MinLength: 2, MaxLength: 2},
+	{Tag: 0x5F34, Name: "Application PAN Sequence Number", Format: FormatNumeric, MinLength: 1, MaxLength: 1},
+	{Tag: 0x5F36, Name: "Transaction Currency Exponent", Format: FormatNumeric, MinLength: 1, MaxLength: 1},
+	{Tag: 0x5F50, Name: "Issuer URL", Format: FormatAlphaNumericSpecial, MinLength: 0, MaxLength: 255},
+	{Tag: 0x61, Name: "Application Template", Format: FormatBinary, MinLength: 0, MaxLength: 252},
+	{Tag: 0x6F, Name: "File Control Information (FCI) Template", Format: FormatBinary, MinLength: 0, MaxLength: 252},
+	{Tag: 0x70, Name: "READ RECORD Response Message Template", Format: FormatBinary, MinLength: 0, MaxLength: 253},
+	{Tag: 0x71, Name: "Issuer Script Template 1", Format: FormatBinary, MinLength: 0, MaxLength: 255},
+	{Tag: 0x72, Name: "Issuer Script Template 2", Format: FormatBinary, MinLength: 0, MaxLength: 255},
+	{Tag: 0x77, Name: "Response Message Template Format 2", Format: FormatBinary, MinLength: 0, MaxLength: 253},
+	{Tag: 0x80, Name: "Response Message Template Format 1", Format: FormatBinary, MinLength: 0, MaxLength: 253},
+	{Tag: 0x82, Name: "Application Interchange Profile", Format: FormatBinary, MinLength: 2, MaxLength: 2},
+	{Tag: 0x84, Name: "Dedicated File (DF) Name", Format: FormatBinary, MinLength: 5, MaxLength: 16},
+	{Tag: 0x86, Name: "Issuer Script Command", Format: FormatBinary, MinLength: 0, MaxLength: 261},
+	{Tag: 0x87, Name: "Application Priority Indicator", Format: FormatBinary, MinLength: 1, MaxLength: 1},
+	{Tag: 0x88, Name: "Short File Identifier (SFI)", Format: FormatBinary, MinLength: 1, MaxLength: 1},
+	{Tag: 0x89, Name: "Authorisation Code", Format: FormatAlphaNumeric, MinLength: 6, MaxLength: 6},
+	{Tag: 0x8A, Name: "Authorisation Response Code", Format: FormatAlphaNumeric, MinLength: 2, MaxLength: 2},
+	{Tag: 0x8C, Name: "Card Risk Management Data Object List 1 (CDOL1)", Format: FormatBinary, MinLength: 0, MaxLength: 252},
+	{Tag: 0x8D, Name: "Card Risk Management Data Object List 2 (CDOL2)", Format: FormatBinary, MinLength: 0, MaxLength: 252},
+	{Tag: 0x8E, Name: "Cardholder Verification Method (CVM) List", Format: FormatBinary, MinLength: 10, MaxLength: 252},
+	{Tag: 0x8F, Name: "Certification Authority Public Key Index", Format: FormatBinary, MinLength: 1, MaxLength: 1},
+	{Tag: 0x90, Name: "Issuer Public Key Certificate", Format: FormatBinary, MinLength: 0, MaxLength: 248},
+	{Tag: 0x91, Name: "Issuer Authentication Data", Format: FormatBinary, MinLength: 8, MaxLength: 16},
+	{Tag: 0x92, Name: "Issuer Public Key Remainder", Format: FormatBinary, MinLength: 0, MaxLength: 255},
+	{Tag: 0x93, Name: "Signed Static Application Data", Format: FormatBinary, MinLength: 0, MaxLength: 248},
+	{Tag: 0x94, Name: "Application File Locator (AFL)", Format: FormatBinary, MinLength: 4, MaxLength: 252},
+	{Tag: 0x95, Name: "Terminal Verification Results", Format: FormatBinary, MinLength: 5, MaxLength: 5},
+	{Tag: 0x9A, Name: "Transaction Date", Format: FormatNumeric, MinLength: 3, MaxLength: 3},
+	{Tag: 0x9B, Name: "Transaction Status Information", Format: FormatBinary, MinLength: 2, MaxLength: 2},
+	{Tag: 0x9C, Name: "Transaction Type", Format: FormatNumeric, MinLength: 1, MaxLength: 1},
+	{Tag: 0x9D, Name: "Directory Definition File (DDF) Name", Format: FormatBinary, MinLength: 5, MaxLength: 16},
+	{Tag: 0x9F01, Name: "Acquirer Identifier", Format: FormatNumeric, MinLength: 6, MaxLength: 6},
+	{Tag: 0x9F02, Name: "Amount, Authorised (Numeric)", Format: FormatNumeric, MinLength: 6, MaxLength: 6},
+	{Tag: 0x9F03, Name: "Amount, Other (Numeric)", Format: FormatNumeric, MinLength: 6, MaxLength: 6},
+	{Tag: 0x9F06, Name: "Application Identifier (AID) - terminal", Format: FormatBinary, MinLength: 5, MaxLength: 16},
+	{Tag: 0x9F07, Name: "Application Usage Control", Format: FormatBinary, MinLength: 2, MaxLength: 2},
+	{Tag: 0x9F08, Name: "Application Version Number (ICC)", Format: FormatBinary, MinLength: 2, MaxLength: 2},
+	{Tag: 0x9F09, Name: "Application Version Number (Terminal)", Format: FormatBinary, MinLength: 2, MaxLength: 2},
+	{Tag: 0x9F0D, Name: "Issuer Action Code - Default", Format: FormatBinary, MinLength: 5, MaxLength: 5},
+	{Tag: 0x9F0E, Name: "Issuer Action Code - Denial", Format: FormatBinary, MinLength: 5, MaxLength: 5},
+	{Tag: 0x9F0F, Name: "Issuer Action Code - Online", Format: FormatBinary, MinLength: 5, MaxLength: 5},
+	{Tag: 0x9F10, Name: "Issuer Application Data", Format: FormatBinary, MinLength: 0, MaxLength: 32},
+	{Tag: 0x9F11, Name: "Issuer Code Table Index", Format: FormatNumeric, MinLength: 1, MaxLength: 1},
+	{Tag: 0x9F12, Name: "Application Preferred Name", Format: FormatAlphaNumericSpecial, MinLength: 1, MaxLength: 16},
+	{Tag: 0x9F13, Name: "Last Online Application Transaction Counter (ATC) Register", Format: FormatBinary, MinLength: 2, MaxLength: 2},
+	{Tag: 0x9F15, Name: "Merchant Category Code", Format: FormatNumeric, MinLength: 2, MaxLength: 2},
+	{Tag: 0x9F16, Name: "Merchant Identifier", Format: FormatAlphaNumericSpecial, MinLength: 15, MaxLength: 15},
+	{Tag: 0x9F17, Name: "Personal Identification Number (PIN) Try Counter", Format: FormatBinary, MinLength: 1, MaxLength: 1},
+	{Tag: 0x9F1A, Name: "Terminal Country Code", Format: FormatNumeric, MinLength: 2, MaxLength: 2},
+	{Tag: 0x9F1B, Name: "Terminal Floor Limit", Format: FormatBinary, MinLength: 4, MaxLength: 4},
+	{Tag: 0x9F1C, Name: "Terminal Identification", Format: FormatAlphaNumeric, MinLength: 8, MaxLength: 8},
+	{Tag: 0x9F1D, Name: "Terminal Risk Management Data", Format: FormatBinary, MinLength: 1, MaxLength: 8},
+	{Tag: 0x9F1E, Name: "Interface Device (IFD) Serial Number", Format: FormatAlphaNumeric, MinLength: 8, MaxLength: 8},
+	{Tag: 0x9F1F, Name: "Track 1 Discretionary Data", Format: FormatAlphaNumericSpecial, MinLength: 0, MaxLength: 54, Sensitive: true},
+	{Tag: 0x9F20, Name: "Track 2 Discretionary Data", Format: FormatCompressedNumeric, MinLength: 0, MaxLength: 16, Sensitive: true},
+	{Tag: 0x9F21, Name: "Transaction Time", Format: FormatNumeric, MinLength: 3, MaxLength: 3},
+	{Tag: 0x9F24, Name: "Payment Account Reference (PAR)", Format: FormatAlphaNumeric, MinLength: 29, MaxLength: 29},
+	{Tag: 0x9F26, Name: "Application Cryptogram", Format: FormatBinary, MinLength: 8, MaxLength: 8},
+	{Tag: 0x9F27, Name: "Cryptogram Information Data", Format: FormatBinary, MinLength: 1, MaxLength: 1},
+	{Tag: 0x9F32, Name: "Issuer Public Key Exponent", Format: FormatBinary, MinLength: 1, MaxLength: 3},
+	{Tag: 0x9F33, Name: "Terminal Capabilities", Format: FormatBinary, MinLength: 3, MaxLength: 3},
+	{Tag: 0x9F34, Name: "Cardholder Verification Method (CVM) Results", Format: FormatBinary, MinLength: 3, MaxLength: 3},
+	{Tag: 0x9F35, Name: "Terminal Type", Format: FormatNumeric, MinLength: 1, MaxLength: 1},
+	{Tag: 0x9F36, Name: "Application Transaction Counter (ATC)", Format: FormatBinary, MinLength: 2, MaxLength: 2},
+	{Tag: 0x9F37, Name: "Unpredictable Number", Format: FormatBinary, MinLength: 4, MaxLength: 4},
+	{Tag: 0x9F38, Name: "Processing Options Data Object List (PDOL)", Format: FormatBinary, MinLength: 0, MaxLength: 252},
+	{Tag: 0x9F39, Name: "Point-of-Service (POS) Entry Mode", Format: FormatNumeric, MinLength: 1, MaxLength: 1},
+	{Tag: 0x9F40, Name: "Additional Terminal Capabilities", Format: FormatBinary, MinLength: 5, MaxLength: 5},
+	{Tag: 0x9F41, Name: "Transaction Sequence Counter", Format: FormatNumeric, MinLength: 2, MaxLength: 4},
+	{Tag: 0x9F42, Name: "Application Currency Code", Format: FormatNumeric, MinLength: 2, MaxLength: 2},
+	{Tag: 0x9F44, Name: "Application Currency Exponent", Format: FormatNumeric, MinLength: 1, MaxLength: 1},
+	{Tag: 0x9F45, Name: "Data Authentication Code", Format: FormatBinary, MinLength: 2, MaxLength: 2},
+	{Tag: 0x9F46, Name: "ICC Public Key Certificate", Format: FormatBinary, MinLength: 0, MaxLength: 248},
+	{Tag: 0x9F47, Name: "ICC Public Key Exponent", Format: FormatBinary, MinLength: 1, MaxLength: 3},
+	{Tag: 0x9F48, Name: "ICC Public Key Remainder", Format: FormatBinary, MinLength: 0, MaxLength: 255},
+	{Tag: 0x9F49, Name: "Dynamic Data Authentication Data Object List (DDOL)", Format: FormatBinary, MinLength: 0, MaxLength: 252},
+	{Tag: 0x9F4A, Name: "Static Data Authentication Tag List", Format: FormatBinary, MinLength: 0, MaxLength: 255},
+	{Tag: 0x9F4B, Name: "Signed Dynamic Application Data", Format: FormatBinary, MinLength: 0, MaxLength: 248},
+	{Tag: 0x9F4C, Name: "ICC Dynamic Number", Format: FormatBinary, MinLength: 2, MaxLength: 8},
+	{Tag: 0x9F4D, Name: "Log Entry", Format: FormatBinary, MinLength: 2, MaxLength: 2},
+	{Tag: 0x9F4E, Name: "Merchant Name and Location", Format: FormatAlphaNumericSpecial, MinLength: 0, MaxLength: 255},
+	{Tag: 0x9F53, Name: "Transaction Category Code", Format: FormatAlphaNumeric, MinLength: 1, MaxLength: 1},
+	{Tag: 0x9F5B, Name: "Issuer Script Results", Format: FormatBinary, MinLength: 0, MaxLength: 255},
+	{Tag: 0x9F66, Name: "Terminal Transaction Qualifiers (TTQ)", Format: FormatBinary, MinLength: 4, MaxLength: 4},
+	{Tag: 0x9F6C, Name: "Card Transaction Qualifiers (CTQ)", Format: FormatBinary, MinLength: 2, MaxLength: 2},
+	{Tag: 0x9F6E, Name: "Form Factor Indicator / Third Party Data", Format: FormatBinary, MinLength: 4, MaxLength: 32},
+	{Tag: 0x9F7C, Name: "Customer Exclusive Data", Format: FormatBinary, MinLength: 0, MaxLength: 32},
+	{Tag: 0xBF0C, Name: "File Control Information (FCI) Issuer Discretionary Data", Format: FormatBinary, MinLength: 0, MaxLength: 222},
+}
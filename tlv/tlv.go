@@ -10,8 +10,9 @@ type Data struct {
 }
 
 type TagData struct {
-	tag   uint32
-	value []byte
+	tag      uint32
+	value    []byte
+	children *Data // value of a constructed tag
 }
 
 func New(data []byte) (*Data, error) {
@@ -26,89 +27,135 @@ func New(data []byte) (*Data, error) {
 	i := 0
 
 	for i < len(data) {
-		// --- Parse Tag ---
-		tag := []byte{data[i]}
-		i++
-
-		// Multi-byte tag check: if 5 LSBs of first tag byte are all 1s (0x1F)
-		// In EMV, if the lower 5 bits of the first byte are all 1 (0x1F), the tag extends into more bytes.
-		// 0x1F is 00011111 in binary
-		// use AND operator to check the tag
-		if tag[0]&0x1F == 0x1F {
-			// Read continuation bytes until MSB = 0
-			for {
-				if i >= len(data) {
-					return nil, fmt.Errorf("unexpected end while reading tag")
-				}
-				tag = append(tag, data[i])
-				i++
-				// 0x80 is 10000000 in binary
-				// use AND operator if the value is 00000000 then break
-				// we found the tag if its MSB = 0
-				if data[i]&0x80 == 0 {
-					break
-				}
-			}
-		}
-
-		// --- Parse Length ---
-		if i >= len(data) {
-			return nil, fmt.Errorf("unexpected end while reading length")
+		tagKey, next, err := readTag(data, i)
+		if err != nil {
+			return nil, err
 		}
-		length := int(data[i])
-		i++
+		i = next
 
-		if length&0x80 != 0 { // Long form length
-			numBytes := length & 0x7F
-			if i+numBytes > len(data) {
-				return nil, fmt.Errorf("invalid length encoding")
-			}
-			length = 0
-			for j := 0; j < numBytes; j++ {
-				length = (length << 8) | int(data[i])
-				i++
-			}
+		length, next, err := readLength(data, i)
+		if err != nil {
+			return nil, err
 		}
+		i = next
 
 		// --- Parse Value ---
-		if i+length > len(data) {
+		if length > len(data)-i {
 			return nil, fmt.Errorf("value exceeds available data")
 		}
 		value := data[i : i+length]
 		i += length
 
-		// Store in map (tag as string)
-		tagKey := BytesToUint32(tag)
-
-		result.list = append(result.list, TagData{
+		tagData := TagData{
 			tag:   tagKey,
 			value: value,
-		})
+		}
+
+		// constructed tags hold nested tlv data, proprietary templates may carry
+		// opaque data instead which is kept as the value without children
+		if IsConstructed(tagKey) {
+			if children, err := New(value); err == nil {
+				tagData.children = children
+			}
+		}
+
+		result.list = append(result.list, tagData)
 	}
 
 	return result, nil
 }
 
-func (t *Data) HasTag(tag uint32) bool {
-	for _, k := range t.list {
-		if k.tag == tag {
-			return true
+// readTag returns the tag starting at i and the index after it
+func readTag(data []byte, i int) (uint32, int, error) {
+	start := i
+	tag := []byte{data[i]}
+	i++
+
+	// Multi-byte tag check: if 5 LSBs of first tag byte are all 1s (0x1F)
+	// In EMV, if the lower 5 bits of the first byte are all 1 (0x1F), the tag extends into more bytes.
+	// 0x1F is 00011111 in binary
+	// use AND operator to check the tag
+	if tag[0]&0x1F == 0x1F {
+		// Read continuation bytes until MSB = 0
+		for {
+			if i >= len(data) {
+				return 0, i, fmt.Errorf("unexpected end while reading tag")
+			}
+			tag = append(tag, data[i])
+			i++
+			// 0x80 is 10000000 in binary
+			// use AND operator if the value is 00000000 then break
+			// we found the tag if its MSB = 0
+			if data[i-1]&0x80 == 0 {
+				break
+			}
 		}
 	}
-	return false
+
+	if len(tag) > 4 {
+		// malformed data may be value bytes read as a tag, leave them out
+		return 0, i, fmt.Errorf("tag at offset %d longer than 4 bytes", start)
+	}
+	return BytesToUint32(tag), i, nil
 }
 
-func (t *Data) GetBytes(tag uint32) []byte {
-	for _, k := range t.list {
-		if k.tag == tag {
-			return k.value
+// readLength returns the length starting at i, in the short or long form, and the index after it
+func readLength(data []byte, i int) (int, int, error) {
+	if i >= len(data) {
+		return 0, i, fmt.Errorf("unexpected end while reading length")
+	}
+	length := int(data[i])
+	i++
+
+	if length&0x80 != 0 { // Long form length
+		// 0x80 is the indefinite form which BER-TLV in EMV does not use,
+		// more than 4 length bytes cannot be a valid length
+		numBytes := length & 0x7F
+		if numBytes == 0 || numBytes > 4 {
+			return 0, i, fmt.Errorf("invalid length encoding: %d length bytes", numBytes)
 		}
+		if i+numBytes > len(data) {
+			return 0, i, fmt.Errorf("invalid length encoding")
+		}
+		length = 0
+		for j := 0; j < numBytes; j++ {
+			length = (length << 8) | int(data[i])
+			i++
+		}
+		// a 4 byte length overflows int on 32-bit platforms
+		if length < 0 {
+			return 0, i, fmt.Errorf("invalid length encoding: length overflows")
+		}
+	}
+	return length, i, nil
+}
+
+// HasTag reports whether the tag is present, a path of tags looks into
+// constructed tags, e.g. HasTag(0x77, 0x9F27)
+func (t *Data) HasTag(path ...uint32) bool {
+	return t.find(path) != nil
+}
+
+// GetBytes returns the value of the tag, the packed nested data of a constructed tag.
+// A path of tags looks into constructed tags, e.g. GetBytes(0x77, 0x9F27).
+func (t *Data) GetBytes(path ...uint32) []byte {
+	if k := t.find(path); k != nil {
+		return k.Value()
 	}
 	return nil
 }
 
-func (t *Data) GetInt64(tag uint32) int64 {
-	b := t.GetBytes(tag)
+// GetData returns the nested data of a constructed tag, changes to it are packed
+// with its parent. It returns nil when the tag is missing, primitive or opaque.
+func (t *Data) GetData(path ...uint32) *Data {
+	if k := t.find(path); k != nil {
+		return k.children
+	}
+	return nil
+}
+
+func (t *Data) GetInt64(path ...uint32) int64 {
+	b := t.GetBytes(path...)
 	if b == nil {
 		return 0
 	}
@@ -119,11 +166,48 @@ func (t *Data) GetInt64(tag uint32) int64 {
 	return result
 }
 
-func (t *Data) GetHexString(tag uint32) string {
-	b := t.GetBytes(tag)
+func (t *Data) GetHexString(path ...uint32) string {
+	b := t.GetBytes(path...)
 	if b == nil {
 		return ""
 	}
 
 	return hex.EncodeToString(b)
 }
+
+// find returns the first tag of the path, looking into the constructed tags
+func (t *Data) find(path []uint32) *TagData {
+	if t == nil || len(path) == 0 {
+		return nil
+	}
+	for i := range t.list {
+		k := &t.list[i]
+		if k.tag != path[0] {
+			continue
+		}
+		if len(path) == 1 {
+			return k
+		}
+		return k.children.find(path[1:])
+	}
+	return nil
+}
+
+// Tag returns the tag
+func (k *TagData) Tag() uint32 {
+	return k.tag
+}
+
+// Value returns the value, the packed nested data of a constructed tag
+func (k *TagData) Value() []byte {
+	if k.children != nil {
+		return k.children.Pack()
+	}
+	return k.value
+}
+
+// Children returns the nested data of a constructed tag, nil for a primitive tag
+// or a constructed tag whose value is not tlv data
+func (k *TagData) Children() *Data {
+	return k.children
+}
@@ -0,0 +1,71 @@
+package tlv
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Dump writes the tags depth-first, one per line with the nested tags indented,
+// their dictionary name, format and length, and the value as digits, text or hex.
+// Sensitive values such as the PAN are masked.
+//
+//	77 Response Message Template Format 2
+//	  9F27 Cryptogram Information Data [b 1]: 80
+//	  9F02 Amount, Authorised (Numeric) [n 6]: 000000001000
+func (t *Data) Dump(w io.Writer) error {
+	var b strings.Builder
+	_ = t.Walk(func(path []uint32, k *TagData) error {
+		info, known := dictionary[k.tag]
+
+		b.WriteString(strings.Repeat("  ", len(path)-1))
+		fmt.Fprintf(&b, "%X", k.tag)
+		if known {
+			b.WriteString(" " + info.Name)
+		}
+		if k.children != nil {
+			b.WriteByte('\n')
+			return nil
+		}
+
+		if known {
+			fmt.Fprintf(&b, " [%s %d]: ", info.Format, len(k.value))
+		} else {
+			fmt.Fprintf(&b, " [%d]: ", len(k.value))
+		}
+		value := formatValue(info, k.value)
+		if info.Sensitive {
+			value = strings.Repeat("*", len(value))
+		}
+		b.WriteString(value)
+		b.WriteByte('\n')
+		return nil
+	})
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// String returns the Dump of the data, sensitive values are masked
+func (t *Data) String() string {
+	var b strings.Builder
+	_ = t.Dump(&b)
+	return b.String()
+}
+
+// formatValue returns the digits of numeric values and the text of the alphanumeric ones,
+// the hex of the value otherwise or when it does not match its format
+func formatValue(info TagInfo, value []byte) string {
+	switch info.Format {
+	case FormatNumeric, FormatCompressedNumeric:
+		if digits, err := decodeNumeric(value, info.Format == FormatCompressedNumeric); err == nil {
+			return digits
+		}
+	case FormatAlpha, FormatAlphaNumeric, FormatAlphaNumericSpecial:
+		if info.Check(value) == nil {
+			return string(value)
+		}
+	}
+	return strings.ToUpper(hex.EncodeToString(value))
+}
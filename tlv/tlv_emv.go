@@ -0,0 +1,139 @@
+package tlv
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrTagNotFound   = errors.New("tag not found")
+	ErrInvalidFormat = errors.New("invalid tag format")
+	ErrInvalidLength = errors.New("invalid tag length")
+)
+
+// GetNumeric returns the digits of a numeric (n) or compressed numeric (cn) value,
+// e.g. "0840" for 5F2A or the PAN of 5A without its 0xF padding.
+// The tag must be n or cn in the dictionary, register proprietary tags with RegisterTag.
+func (t *Data) GetNumeric(path ...uint32) (string, error) {
+	return t.getNumeric(path, FormatNumeric, FormatCompressedNumeric)
+}
+
+// GetAmount returns an n12 amount such as 9F02 or 9F03 in minor units
+func (t *Data) GetAmount(path ...uint32) (int64, error) {
+	digits, err := t.getNumeric(path, FormatNumeric)
+	if err != nil {
+		return 0, err
+	}
+	if digits == "" || len(digits) > 18 {
+		return 0, fmt.Errorf("%w: amount of %d digits", ErrInvalidLength, len(digits))
+	}
+	return strconv.ParseInt(digits, 10, 64)
+}
+
+// GetDate returns an n6 YYMMDD date such as 9A, 5F24 or 5F25 in UTC
+func (t *Data) GetDate(path ...uint32) (time.Time, error) {
+	digits, err := t.getNumeric(path, FormatNumeric)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(digits) != len("060102") {
+		return time.Time{}, fmt.Errorf("%w: date of %d digits", ErrInvalidLength, len(digits))
+	}
+	date, err := time.Parse("060102", digits)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: not a YYMMDD date", ErrInvalidFormat)
+	}
+	return date, nil
+}
+
+// getNumeric returns the digits of the tag once its dictionary format is one of the formats
+func (t *Data) getNumeric(path []uint32, formats ...Format) (string, error) {
+	k := t.find(path)
+	if k == nil {
+		return "", fmt.Errorf("%w: %X", ErrTagNotFound, path)
+	}
+	info, ok := dictionary[k.tag]
+	if !ok {
+		return "", fmt.Errorf("%w: tag %X is not in the dictionary", ErrInvalidFormat, k.tag)
+	}
+	if !slices.Contains(formats, info.Format) {
+		return "", fmt.Errorf("%w: tag %X is %s, expected %s", ErrInvalidFormat, k.tag, info.Format, formats)
+	}
+	digits, err := decodeNumeric(k.Value(), info.Format == FormatCompressedNumeric)
+	if err != nil {
+		return "", fmt.Errorf("tag %X: %w", k.tag, err)
+	}
+	return digits, nil
+}
+
+// Validate checks the length and format of every tag found in the dictionary,
+// nested tags included. Unknown tags are not checked. All the errors are joined.
+func (t *Data) Validate() error {
+	var errs []error
+	_ = t.Walk(func(path []uint32, k *TagData) error {
+		info, ok := dictionary[k.tag]
+		if !ok {
+			return nil
+		}
+		if err := info.Check(k.Value()); err != nil {
+			errs = append(errs, fmt.Errorf("tag %X %s: %w", path, info.Name, err))
+		}
+		return nil
+	})
+	return errors.Join(errs...)
+}
+
+// Check checks the length and format of the value
+func (info TagInfo) Check(value []byte) error {
+	if len(value) < info.MinLength || (info.MaxLength > 0 && len(value) > info.MaxLength) {
+		return fmt.Errorf("%w: %d bytes, expected %d to %d", ErrInvalidLength, len(value), info.MinLength, info.MaxLength)
+	}
+
+	switch info.Format {
+	case FormatNumeric, FormatCompressedNumeric:
+		_, err := decodeNumeric(value, info.Format == FormatCompressedNumeric)
+		return err
+	case FormatAlpha, FormatAlphaNumeric, FormatAlphaNumericSpecial:
+		for i, c := range value {
+			if !validChar(info.Format, c) {
+				return fmt.Errorf("%w: byte %d is not %s", ErrInvalidFormat, i, info.Format)
+			}
+		}
+	}
+	return nil
+}
+
+func validChar(format Format, c byte) bool {
+	letter := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+	switch format {
+	case FormatAlpha:
+		return letter
+	case FormatAlphaNumeric:
+		return letter || c >= '0' && c <= '9' || c == ' '
+	default:
+		return c >= 0x20 && c != 0x7F
+	}
+}
+
+// decodeNumeric returns the BCD digits, compressed numeric values may end with 0xF nibbles.
+// The error gives the position only, the value may be card holder data.
+func decodeNumeric(b []byte, compressed bool) (string, error) {
+	digits := make([]byte, 0, len(b)*2)
+	padding := false
+	for i, v := range b {
+		for _, nibble := range [2]byte{v >> 4, v & 0x0F} {
+			switch {
+			case nibble == 0x0F && compressed:
+				padding = true
+			case nibble <= 9 && !padding:
+				digits = append(digits, '0'+nibble)
+			default:
+				return "", fmt.Errorf("%w: byte %d is not numeric", ErrInvalidFormat, i)
+			}
+		}
+	}
+	return string(digits), nil
+}
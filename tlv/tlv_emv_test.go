@@ -0,0 +1,86 @@
+package tlv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEMVTestData(t *testing.T) *Data {
+	t.Helper()
+	data := &Data{}
+	for tag, value := range map[uint32][]byte{
+		0x9F02: {0x00, 0x00, 0x00, 0x00, 0x10, 0x00},
+		0x9A:   {0x25, 0x12, 0x31},
+		0x5F2A: {0x08, 0x40},
+		0x5A:   {0x41, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x1F},
+		0x9F27: {0x80},
+		0x5F24: {0x25, 0x13, 0x31},
+		0xDF01: {0x12},
+	} {
+		require.NoError(t, data.Append(tag, value))
+	}
+	return data
+}
+
+func TestTypedGetters(t *testing.T) {
+	data := newEMVTestData(t)
+
+	amount, err := data.GetAmount(0x9F02)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), amount)
+
+	date, err := data.GetDate(0x9A)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC), date)
+
+	currency, err := data.GetNumeric(0x5F2A)
+	require.NoError(t, err)
+	assert.Equal(t, "0840", currency)
+
+	pan, err := data.GetNumeric(0x5A)
+	require.NoError(t, err)
+	assert.Equal(t, "411111111111111", pan)
+}
+
+func TestTypedGettersCheckFormat(t *testing.T) {
+	data := newEMVTestData(t)
+
+	_, err := data.GetAmount(0x9F27)
+	assert.ErrorIs(t, err, ErrInvalidFormat, "binary CID is not an amount")
+
+	_, err = data.GetNumeric(0x9F27)
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+
+	_, err = data.GetAmount(0x5A)
+	assert.ErrorIs(t, err, ErrInvalidFormat, "compressed numeric is not an amount")
+
+	_, err = data.GetNumeric(0xDF01)
+	assert.ErrorIs(t, err, ErrInvalidFormat, "unknown tag")
+
+	_, err = data.GetDate(0x5F2A)
+	assert.ErrorIs(t, err, ErrInvalidLength)
+
+	_, err = data.GetDate(0x5F24)
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+
+	_, err = data.GetAmount(0x9F03)
+	assert.ErrorIs(t, err, ErrTagNotFound)
+}
+
+func TestValidateDictionary(t *testing.T) {
+	data := &Data{}
+	require.NoError(t, data.Append(0x9F02, []byte{0x00, 0x01}))
+	require.NoError(t, data.Append(0x5F2A, []byte{0x08, 0x4A}))
+	require.NoError(t, data.Append(0x50, []byte("VISA\x00")))
+
+	err := data.Validate()
+	assert.ErrorIs(t, err, ErrInvalidLength)
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+	assert.Len(t, err.(interface{ Unwrap() []error }).Unwrap(), 3)
+
+	// the dictionary checks the format only, 5F24 with month 13 is still n
+	assert.NoError(t, newEMVTestData(t).Validate())
+}
@@ -5,17 +5,45 @@ import (
 	"slices"
 )
 
+// Append adds the tag, the value of a constructed tag is parsed into nested data.
+// A constructed value that is not tlv data is kept as opaque bytes, as in New.
 func (t *Data) Append(tag uint32, v []byte) error {
-	if tag == 0 {
-		return fmt.Errorf("tag cannot be 0")
+	if err := ValidateTag(tag); err != nil {
+		return err
 	}
 	if v == nil {
 		return fmt.Errorf("append data cannot be nil")
 	}
 
-	t.list = append(t.list, TagData{
+	tagData := TagData{
 		tag:   tag,
 		value: v,
+	}
+	if IsConstructed(tag) {
+		if children, err := New(v); err == nil {
+			tagData.children = children
+		}
+	}
+	t.list = append(t.list, tagData)
+
+	return nil
+}
+
+// AppendData adds a constructed tag with the nested data as its value
+func (t *Data) AppendData(tag uint32, children *Data) error {
+	if err := ValidateTag(tag); err != nil {
+		return err
+	}
+	if !IsConstructed(tag) {
+		return fmt.Errorf("tag %X is not constructed", tag)
+	}
+	if children == nil {
+		children = &Data{}
+	}
+
+	t.list = append(t.list, TagData{
+		tag:      tag,
+		children: children,
 	})
 
 	return nil
@@ -29,7 +57,7 @@ func (t *Data) Remove(tag uint32) error {
 		return false
 	})
 	if i == -1 {
-		return fmt.Errorf("tag not found")
+		return fmt.Errorf("%w: %X", ErrTagNotFound, tag)
 	}
 
 	t.list = append(t.list[:i], t.list[i+1:]...)
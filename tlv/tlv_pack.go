@@ -1,16 +1,68 @@
 package tlv
 
+// Pack encodes the data in BER-TLV: multi-byte tags as they are, lengths of
+// 128 bytes or more in the long form (0x81 to 0x84 followed by the length bytes)
+// and constructed tags with their nested data packed as the value
 func (t *Data) Pack() []byte {
-	if t.list == nil || len(t.list) == 0 {
+	if t == nil || len(t.list) == 0 {
 		return nil
 	}
-	result := make([]byte, 0)
+	result := make([]byte, 0, t.packedSize())
+	return t.appendPacked(result)
+}
+
+func (t *Data) appendPacked(result []byte) []byte {
 	for _, v := range t.list {
-		length := len(v.value)
 		result = append(result, Uint32ToBytes(v.tag)...)
-		result = append(result, byte(length))
+		if v.children != nil {
+			result = appendLength(result, v.children.packedSize())
+			result = v.children.appendPacked(result)
+			continue
+		}
+		result = appendLength(result, len(v.value))
 		result = append(result, v.value...)
 	}
-
 	return result
 }
+
+// packedSize returns the number of bytes Pack writes
+func (t *Data) packedSize() int {
+	size := 0
+	for _, v := range t.list {
+		length := len(v.value)
+		if v.children != nil {
+			length = v.children.packedSize()
+		}
+		size += len(Uint32ToBytes(v.tag)) + lengthSize(length) + length
+	}
+	return size
+}
+
+// appendLength appends the BER length, the short form below 128
+func appendLength(b []byte, length int) []byte {
+	if length < 0x80 {
+		return append(b, byte(length))
+	}
+	n := lengthSize(length) - 1
+	b = append(b, 0x80|byte(n))
+	for i := n - 1; i >= 0; i-- {
+		b = append(b, byte(length>>(8*i)))
+	}
+	return b
+}
+
+// lengthSize returns the number of bytes of the BER length
+func lengthSize(length int) int {
+	switch {
+	case length < 0x80:
+		return 1
+	case length <= 0xFF:
+		return 2
+	case length <= 0xFFFF:
+		return 3
+	case length <= 0xFFFFFF:
+		return 4
+	default:
+		return 5
+	}
+}
@@ -0,0 +1,80 @@
+package tlv
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackLongFormLengths(t *testing.T) {
+	tests := []struct {
+		name   string
+		length int
+		header []byte
+	}{
+		{"short form", 0x7F, []byte{0x9F, 0x10, 0x7F}},
+		{"one length byte", 0x80, []byte{0x9F, 0x10, 0x81, 0x80}},
+		{"two length bytes", 0x100, []byte{0x9F, 0x10, 0x82, 0x01, 0x00}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value := bytes.Repeat([]byte{0xAB}, tt.length)
+			data := &Data{}
+			require.NoError(t, data.Append(0x9F10, value))
+
+			packed := data.Pack()
+			assert.Equal(t, tt.header, packed[:len(tt.header)])
+			assert.Len(t, packed, len(tt.header)+tt.length)
+
+			out, err := New(packed)
+			require.NoError(t, err)
+			assert.Equal(t, value, out.GetBytes(0x9F10))
+		})
+	}
+}
+
+func TestPackConstructed(t *testing.T) {
+	record := &Data{}
+	require.NoError(t, record.Append(0x5A, []byte{0x41, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11}))
+	require.NoError(t, record.Append(0x5F24, []byte{0x26, 0x12, 0x31}))
+
+	data := &Data{}
+	require.NoError(t, data.AppendData(0x70, record))
+	require.NoError(t, data.Append(0x9F02, []byte{0x00, 0x00, 0x00, 0x00, 0x10, 0x00}))
+
+	packed := data.Pack()
+	assert.Equal(t, []byte{
+		0x70, 0x10,
+		0x5A, 0x08, 0x41, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11,
+		0x5F, 0x24, 0x03, 0x26, 0x12, 0x31,
+		0x9F, 0x02, 0x06, 0x00, 0x00, 0x00, 0x00, 0x10, 0x00,
+	}, packed)
+
+	out, err := New(packed)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x26, 0x12, 0x31}, out.GetBytes(0x70, 0x5F24))
+	assert.Equal(t, packed, out.Pack())
+
+	require.NoError(t, record.Remove(0x5A))
+	assert.Equal(t, []byte{0x70, 0x06, 0x5F, 0x24, 0x03, 0x26, 0x12, 0x31}, data.Pack()[:8],
+		"the constructed length follows its children")
+}
+
+func TestPackEmpty(t *testing.T) {
+	assert.Nil(t, (&Data{}).Pack())
+	assert.Nil(t, (*Data)(nil).Pack())
+
+	data := &Data{}
+	require.NoError(t, data.AppendData(0x70, nil))
+	assert.Equal(t, []byte{0x70, 0x00}, data.Pack())
+}
+
+func TestModifyErrors(t *testing.T) {
+	data := &Data{}
+	assert.Error(t, data.Append(0, []byte{0x01}))
+	assert.Error(t, data.Append(0x5A, nil))
+	assert.Error(t, data.AppendData(0x5A, &Data{}), "the tag is primitive")
+	assert.ErrorIs(t, data.Remove(0x5A), ErrTagNotFound)
+}
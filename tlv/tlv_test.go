@@ -0,0 +1,61 @@
+package tlv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadLength(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   []byte
+		length int
+		next   int
+		valid  bool
+	}{
+		{"short form", []byte{0x7F}, 0x7F, 1, true},
+		{"one length byte", []byte{0x81, 0x80}, 0x80, 2, true},
+		{"two length bytes", []byte{0x82, 0x01, 0x00}, 0x100, 3, true},
+		{"four length bytes", []byte{0x84, 0x7F, 0xFF, 0xFF, 0xFF}, 0x7FFFFFFF, 5, true},
+		{"indefinite form", []byte{0x80}, 0, 0, false},
+		{"five length bytes", []byte{0x85, 0x00, 0x00, 0x00, 0x00, 0x01}, 0, 0, false},
+		{"eight length bytes", []byte{0x88, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, 0, 0, false},
+		{"truncated", []byte{0x82, 0x01}, 0, 0, false},
+		{"empty", []byte{}, 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			length, next, err := readLength(tt.data, 0)
+			if !tt.valid {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.length, length)
+			assert.Equal(t, tt.next, next)
+		})
+	}
+}
+
+func TestNewMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"long form length of 8 bytes", []byte{0x9A, 0x88, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}},
+		{"length past the end", []byte{0x9A, 0x84, 0x7F, 0xFF, 0xFF, 0xFF, 0x25}},
+		{"value past the end", []byte{0x9A, 0x03, 0x25, 0x12}},
+		{"truncated tag", []byte{0x9F}},
+		{"missing length", []byte{0x9F, 0x02}},
+		{"tag longer than 4 bytes", []byte{0xDF, 0x81, 0x81, 0x81, 0x01, 0x00}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			require.NotPanics(t, func() { _, err = New(tt.data) })
+			assert.Error(t, err)
+		})
+	}
+}
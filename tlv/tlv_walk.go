@@ -0,0 +1,40 @@
+package tlv
+
+import "errors"
+
+// SkipChildren is returned by a WalkFunc to skip the nested data of a constructed tag
+var SkipChildren = errors.New("skip children")
+
+// WalkFunc is called for every tag with its path from the top level tag,
+// constructed tags are visited before their children
+type WalkFunc func(path []uint32, tag *TagData) error
+
+// Walk visits the tags depth-first in order. It stops at the first error
+// returned by fn, except SkipChildren, and returns it.
+// The path is reused between calls, clone it to keep it.
+func (t *Data) Walk(fn WalkFunc) error {
+	return t.walk(make([]uint32, 0, 4), fn)
+}
+
+func (t *Data) walk(path []uint32, fn WalkFunc) error {
+	if t == nil {
+		return nil
+	}
+	for i := range t.list {
+		k := &t.list[i]
+		path := append(path, k.tag)
+
+		err := fn(path, k)
+		if errors.Is(err, SkipChildren) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if err = k.children.walk(path, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package tlv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConstructedPath(t *testing.T) {
+	// 77 { 9F27 80, 9F36 0001 }, 9A 251231
+	data, err := New([]byte{0x77, 0x09, 0x9F, 0x27, 0x01, 0x80, 0x9F, 0x36, 0x02, 0x00, 0x01, 0x9A, 0x03, 0x25, 0x12, 0x31})
+	require.NoError(t, err)
+
+	assert.True(t, data.HasTag(0x77, 0x9F27))
+	assert.False(t, data.HasTag(0x9F27))
+	assert.Equal(t, []byte{0x80}, data.GetBytes(0x77, 0x9F27))
+	assert.Equal(t, "0001", data.GetHexString(0x77, 0x9F36))
+	assert.Nil(t, data.GetData(0x9A))
+
+	require.NoError(t, data.GetData(0x77).Append(0x9F26, []byte{0x01}))
+	assert.Equal(t, []byte{0x9F, 0x27, 0x01, 0x80, 0x9F, 0x36, 0x02, 0x00, 0x01, 0x9F, 0x26, 0x01, 0x01}, data.GetBytes(0x77))
+}
+
+func TestNewConstructedOpaqueValue(t *testing.T) {
+	raw := []byte{0xE1, 0x02, 0xFF, 0xFF, 0x9A, 0x01, 0x25}
+	data, err := New(raw)
+	require.NoError(t, err)
+
+	assert.Nil(t, data.GetData(0xE1))
+	assert.Equal(t, []byte{0xFF, 0xFF}, data.GetBytes(0xE1))
+	assert.Equal(t, raw, data.Pack())
+}
+
+func TestAppendConstructedOpaqueValue(t *testing.T) {
+	data := &Data{}
+	require.NoError(t, data.Append(0x70, []byte{0x5A, 0x08, 0x41}))
+
+	assert.Nil(t, data.GetData(0x70))
+	assert.Equal(t, []byte{0x5A, 0x08, 0x41}, data.GetBytes(0x70))
+	assert.Equal(t, []byte{0x70, 0x03, 0x5A, 0x08, 0x41}, data.Pack())
+
+	parsed, err := New(data.Pack())
+	require.NoError(t, err)
+	assert.Equal(t, data.Pack(), parsed.Pack(), "the opaque value round trips as in New")
+}
+
+func TestWalk(t *testing.T) {
+	data, err := New([]byte{0x70, 0x06, 0x77, 0x04, 0x9F, 0x27, 0x01, 0x80, 0x9A, 0x01, 0x25})
+	require.NoError(t, err)
+
+	var paths [][]uint32
+	err = data.Walk(func(path []uint32, tag *TagData) error {
+		paths = append(paths, append([]uint32(nil), path...))
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, [][]uint32{{0x70}, {0x70, 0x77}, {0x70, 0x77, 0x9F27}, {0x9A}}, paths)
+
+	var tags []uint32
+	err = data.Walk(func(path []uint32, tag *TagData) error {
+		tags = append(tags, tag.Tag())
+		if tag.Tag() == 0x77 {
+			return SkipChildren
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{0x70, 0x77, 0x9A}, tags)
+}
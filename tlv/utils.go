@@ -1,5 +1,7 @@
 package tlv
 
+import "fmt"
+
 func BytesToUint32(b []byte) uint32 {
 	var n uint32
 	for _, v := range b {
@@ -39,3 +41,43 @@ func Uint32ToBytes(n uint32) []byte {
 	return b[i:]
 
 }
+
+// firstTagByte returns the first byte of the tag
+func firstTagByte(tag uint32) byte {
+	b := Uint32ToBytes(tag)
+	if len(b) == 0 {
+		return 0
+	}
+	return b[0]
+}
+
+// IsConstructed reports whether the tag is constructed, bit 6 of its first byte is set
+func IsConstructed(tag uint32) bool {
+	return firstTagByte(tag)&0x20 != 0
+}
+
+// ValidateTag checks the tag is a BER tag: a single byte whose 5 low bits are not all 1,
+// or a first byte with the 5 low bits set followed by bytes with the MSB set
+// except the last one
+func ValidateTag(tag uint32) error {
+	b := Uint32ToBytes(tag)
+	if len(b) == 0 {
+		return fmt.Errorf("tag cannot be 0")
+	}
+	if len(b) == 1 {
+		if b[0]&0x1F == 0x1F {
+			return fmt.Errorf("tag %X is missing its subsequent bytes", tag)
+		}
+		return nil
+	}
+	if b[0]&0x1F != 0x1F {
+		return fmt.Errorf("tag %X has subsequent bytes without 0x1F in its first byte", tag)
+	}
+	for i, v := range b[1:] {
+		last := i == len(b)-2
+		if (v&0x80 == 0) != last {
+			return fmt.Errorf("invalid subsequent byte %02X in tag %X", v, tag)
+		}
+	}
+	return nil
+}
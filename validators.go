@@ -0,0 +1,169 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// RuleValidator is the rule name of a RuleViolation reported by a registered FieldValidator
+const RuleValidator = "validator"
+
+var (
+	ErrInvalidLuhn         = errors.New("invalid luhn check digit")
+	ErrInvalidDateTime     = errors.New("invalid date time")
+	ErrInvalidCurrencyCode = errors.New("invalid iso 4217 currency code")
+	ErrMCCNotAllowed       = errors.New("merchant category code not allowed")
+)
+
+// FieldValidator checks the value of a bit and returns why it is invalid
+type FieldValidator func(value string) error
+
+// RegisterValidator adds validators to the bit, they run in Message.Validate after
+// the length and charset checks pass. Register them before the packager is shared.
+func (p *IsoPackager) RegisterValidator(bit int, validators ...FieldValidator) error {
+	if bit < 2 || bit > MaxBitNumber {
+		return fmt.Errorf("%w: %d", ErrInvalidBitNumber, bit)
+	}
+	p.validators[bit] = append(p.validators[bit], validators...)
+	return nil
+}
+
+// RegisterDefaultValidators registers the built-in validators of the common fields of the
+// ISO 8583 version of the packager: Luhn on DE 2, MMDDhhmmss on DE 7, YYMM on DE 14 and
+// ISO 4217 on the currency codes DE 49, 50 and 51. DE 13 is checked as MMDD for 1987 and
+// as the YYMM effective date for 1993 and 2003, which also check DE 12 as YYMMDDhhmmss.
+func (p *IsoPackager) RegisterDefaultValidators(version MTIVersion) {
+	p.validators[2] = append(p.validators[2], ValidateLuhn)
+	p.validators[7] = append(p.validators[7], ValidateMMDDhhmmss)
+	if version.is1993() {
+		p.validators[12] = append(p.validators[12], ValidateYYMMDDhhmmss)
+		p.validators[13] = append(p.validators[13], ValidateYYMM)
+	} else {
+		p.validators[13] = append(p.validators[13], ValidateMMDD)
+	}
+	p.validators[14] = append(p.validators[14], ValidateYYMM)
+	for _, bit := range []int{49, 50, 51} {
+		p.validators[bit] = append(p.validators[bit], ValidateCurrencyCode)
+	}
+}
+
+// runValidators returns a violation for every registered validator the bit fails
+func (m *Message) runValidators(bit int) (violations []*RuleViolation) {
+	for _, validate := range m.packager.validators[bit] {
+		if err := validate(m.GetString(bit)); err != nil {
+			violations = append(violations, &RuleViolation{Bit: bit, Rule: RuleValidator, Detail: err.Error()})
+		}
+	}
+	return violations
+}
+
+// ValidateLuhn checks the Luhn (mod 10) check digit of a card number
+func ValidateLuhn(value string) error {
+	if len(value) < 2 {
+		return ErrInvalidLuhn
+	}
+	sum := 0
+	double := false
+	for i := len(value) - 1; i >= 0; i-- {
+		c := value[i]
+		if c < '0' || c > '9' {
+			return ErrInvalidLuhn
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	if sum%10 != 0 {
+		return ErrInvalidLuhn
+	}
+	return nil
+}
+
+// ValidateMMDDhhmmss checks a transmission date and time, e.g. DE 7
+func ValidateMMDDhhmmss(value string) error {
+	return validateDateTime("0102150405", value)
+}
+
+// ValidateYYMMDDhhmmss checks a local date and time, e.g. DE 12 of ISO 8583:1993
+func ValidateYYMMDDhhmmss(value string) error {
+	return validateDateTime("060102150405", value)
+}
+
+// ValidateMMDD checks a month and day, e.g. DE 13
+func ValidateMMDD(value string) error {
+	return validateDateTime("0102", value)
+}
+
+// ValidateYYMM checks an expiration date, e.g. DE 14, or the effective date DE 13 of ISO 8583:1993
+func ValidateYYMM(value string) error {
+	return validateDateTime("0601", value)
+}
+
+// ValidateHHMMSS checks a local time, e.g. DE 12 of ISO 8583:1987
+func ValidateHHMMSS(value string) error {
+	return validateDateTime("150405", value)
+}
+
+// validateDateTime checks the value against the layout, the error leaves the value out
+// as the date fields may sit next to card data in the logs
+func validateDateTime(layout, value string) error {
+	if len(value) != len(layout) {
+		return fmt.Errorf("%w: expected %d digits, got %d", ErrInvalidDateTime, len(layout), len(value))
+	}
+	if _, err := time.Parse(layout, value); err != nil {
+		return ErrInvalidDateTime
+	}
+	return nil
+}
+
+// ValidateCurrencyCode checks an ISO 4217 numeric currency code, e.g. DE 49
+func ValidateCurrencyCode(value string) error {
+	if _, ok := currencyCodes[value]; !ok {
+		return ErrInvalidCurrencyCode
+	}
+	return nil
+}
+
+// MCCWhitelist returns a validator accepting only the merchant category codes, e.g. for DE 18
+func MCCWhitelist(mccs ...string) FieldValidator {
+	allowed := slices.Clone(mccs)
+	return func(value string) error {
+		if !slices.Contains(allowed, value) {
+			return ErrMCCNotAllowed
+		}
+		return nil
+	}
+}
+
+// currencyCodes are the active ISO 4217 numeric currency codes
+var currencyCodes = map[string]struct{}{
+	"008": {}, "012": {}, "032": {}, "036": {}, "044": {}, "048": {}, "050": {}, "051": {},
+	"052": {}, "060": {}, "064": {}, "068": {}, "072": {}, "084": {}, "090": {}, "096": {},
+	"104": {}, "108": {}, "116": {}, "124": {}, "132": {}, "136": {}, "144": {}, "152": {},
+	"156": {}, "170": {}, "174": {}, "188": {}, "192": {}, "203": {}, "208": {}, "214": {},
+	"222": {}, "230": {}, "232": {}, "238": {}, "242": {}, "262": {}, "270": {}, "292": {},
+	"320": {}, "324": {}, "328": {}, "332": {}, "340": {}, "344": {}, "348": {}, "352": {},
+	"356": {}, "360": {}, "364": {}, "368": {}, "376": {}, "388": {}, "392": {}, "398": {},
+	"400": {}, "404": {}, "408": {}, "410": {}, "414": {}, "417": {}, "418": {}, "422": {},
+	"426": {}, "430": {}, "434": {}, "446": {}, "454": {}, "458": {}, "462": {}, "480": {},
+	"484": {}, "496": {}, "498": {}, "504": {}, "512": {}, "516": {}, "524": {}, "532": {},
+	"533": {}, "548": {}, "554": {}, "558": {}, "566": {}, "578": {}, "586": {}, "590": {},
+	"598": {}, "600": {}, "604": {}, "608": {}, "634": {}, "643": {}, "646": {}, "654": {},
+	"682": {}, "690": {}, "702": {}, "704": {}, "706": {}, "710": {}, "728": {}, "748": {},
+	"752": {}, "756": {}, "760": {}, "764": {}, "776": {}, "780": {}, "784": {}, "788": {},
+	"800": {}, "807": {}, "818": {}, "826": {}, "834": {}, "840": {}, "858": {}, "860": {},
+	"882": {}, "886": {}, "901": {}, "924": {}, "925": {}, "926": {}, "927": {}, "928": {},
+	"929": {}, "930": {}, "931": {}, "932": {}, "933": {}, "934": {}, "936": {}, "938": {},
+	"940": {}, "941": {}, "943": {}, "944": {}, "946": {}, "947": {}, "948": {}, "949": {},
+	"950": {}, "951": {}, "952": {}, "953": {}, "967": {}, "968": {}, "969": {}, "970": {},
+	"971": {}, "972": {}, "973": {}, "975": {}, "976": {}, "977": {}, "978": {}, "980": {},
+	"981": {}, "984": {}, "985": {}, "986": {}, "990": {}, "997": {},
+}
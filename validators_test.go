@@ -0,0 +1,112 @@
+package iso8583
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldValidators(t *testing.T) {
+	tests := []struct {
+		name     string
+		validate FieldValidator
+		value    string
+		err      error
+	}{
+		{"luhn valid", ValidateLuhn, "4111111111111111", nil},
+		{"luhn check digit", ValidateLuhn, "4111111111111112", ErrInvalidLuhn},
+		{"luhn not numeric", ValidateLuhn, "41111111111111A1", ErrInvalidLuhn},
+		{"luhn too short", ValidateLuhn, "4", ErrInvalidLuhn},
+		{"MMDDhhmmss valid", ValidateMMDDhhmmss, "1231235959", nil},
+		{"MMDDhhmmss hour", ValidateMMDDhhmmss, "1231245959", ErrInvalidDateTime},
+		{"YYMMDDhhmmss valid", ValidateYYMMDDhhmmss, "251231235959", nil},
+		{"YYMMDDhhmmss length", ValidateYYMMDDhhmmss, "1231235959", ErrInvalidDateTime},
+		{"MMDD valid", ValidateMMDD, "0229", nil},
+		{"MMDD month", ValidateMMDD, "1301", ErrInvalidDateTime},
+		{"YYMM valid", ValidateYYMM, "2512", nil},
+		{"YYMM month", ValidateYYMM, "2513", ErrInvalidDateTime},
+		{"HHMMSS valid", ValidateHHMMSS, "235959", nil},
+		{"currency valid", ValidateCurrencyCode, "840", nil},
+		{"currency unknown", ValidateCurrencyCode, "000", ErrInvalidCurrencyCode},
+		{"mcc allowed", MCCWhitelist("5411"), "5411", nil},
+		{"mcc not allowed", MCCWhitelist("5411"), "5812", ErrMCCNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.validate(tt.value)
+			if tt.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestValidatorErrorsLeaveValueOut(t *testing.T) {
+	for _, validate := range []FieldValidator{ValidateLuhn, ValidateMMDD, ValidateYYMMDDhhmmss, ValidateCurrencyCode, MCCWhitelist("5411")} {
+		err := validate("4111111111111112")
+		if assert.Error(t, err) {
+			assert.NotContains(t, err.Error(), "4111111111111112")
+		}
+	}
+
+	packager := DefaultPackager()
+	packager.RegisterDefaultValidators(MTIVersion1987)
+	require.NoError(t, packager.RegisterValidator(18, MCCWhitelist("5411")))
+	msg := NewMessage(packager)
+	msg.SetMtiString("0200")
+	msg.SetString(18, "5812").SetString(49, "999")
+
+	var violations []string
+	for _, v := range msg.Validate().Violations {
+		if v.Rule == RuleValidator {
+			violations = append(violations, v.Error())
+		}
+	}
+	assert.ElementsMatch(t, []string{
+		"bit 18 validator: merchant category code not allowed",
+		"bit 49 validator: invalid iso 4217 currency code",
+	}, violations)
+}
+
+func TestRegisterDefaultValidatorsByVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  MTIVersion
+		packager func() *IsoPackager
+		mti      MTIType
+		de12     string
+		de13     string
+		bits     []int
+	}{
+		{"1987 MMDD", MTIVersion1987, DefaultPackager, "0200", "120000", "1231", nil},
+		{"1987 YYMM is not MMDD", MTIVersion1987, DefaultPackager, "0200", "120000", "2513", []int{13}},
+		{"1993 YYMM effective date", MTIVersion1993, DefaultPackager1993, "1200", "251231120000", "2512", nil},
+		{"1993 bad date", MTIVersion1993, DefaultPackager1993, "1200", "251231250000", "2513", []int{12, 13}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packager := tt.packager()
+			packager.RegisterDefaultValidators(tt.version)
+
+			msg := NewMessage(packager)
+			msg.SetMtiString(tt.mti)
+			msg.SetString(2, "4111111111111112")
+			msg.SetString(7, "1231235959")
+			msg.SetString(12, tt.de12)
+			msg.SetString(13, tt.de13)
+
+			var bits []int
+			for _, v := range msg.Validate().Violations {
+				if v.Rule != RuleValidator {
+					continue
+				}
+				bits = append(bits, v.Bit)
+				assert.NotContains(t, v.Detail, "4111111111111112")
+			}
+			assert.Equal(t, append([]int{2}, tt.bits...), bits)
+		})
+	}
+}
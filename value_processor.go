@@ -0,0 +1,118 @@
+package iso8583
+
+import (
+	"fmt"
+)
+
+// valueSize returns the number of bytes a value of n characters takes on the wire
+func (p *IsoPackager) valueSize(bitNum, n int) int {
+	if p.ValueEncodings[bitNum] == EncodingBCD {
+		return (n + 1) / 2
+	}
+	return n
+}
+
+// encodeValueInto writes the value of the bit into dst using the bit value encoding
+// and returns the number of bytes written.
+func (m *Message) encodeValueInto(bitNum int, value []byte, dst []byte) (int, error) {
+	encoding := m.packager.ValueEncodings[bitNum]
+	if encoding.isEBCDIC() {
+		return asciiToEBCDIC(dst, value, encoding), nil
+	}
+	if encoding != EncodingBCD {
+		return copy(dst, value), nil
+	}
+
+	config := &m.packager.IsoPackagerConfig[bitNum]
+	filler, _ := hexNibble(config.Filler[0])
+
+	// pad odd lengths with the filler nibble on the configured side
+	pad := len(value) % 2
+	leftPad := pad == 1 && config.Padding != PaddingRight
+
+	size := (len(value) + 1) / 2
+	for i := 0; i < size; i++ {
+		var hi, lo byte
+		var ok bool
+
+		idx := i * 2
+		if leftPad {
+			idx--
+		}
+
+		if idx < 0 {
+			hi = filler
+		} else if hi, ok = hexNibble(value[idx]); !ok {
+			return 0, fmt.Errorf("invalid bcd value for bit %d: %q", bitNum, value[idx])
+		}
+
+		if idx+1 >= len(value) {
+			lo = filler
+		} else if lo, ok = hexNibble(value[idx+1]); !ok {
+			return 0, fmt.Errorf("invalid bcd value for bit %d: %q", bitNum, value[idx+1])
+		}
+
+		dst[i] = hi<<4 | lo
+	}
+
+	return size, nil
+}
+
+// decodeValue returns the value of the bit with length characters from src.
+// ASCII values are returned as a slice of src, other encodings are decoded
+// into the message value buffer.
+func (m *Message) decodeValue(bitNum int, src []byte, length int) ([]byte, error) {
+	encoding := m.packager.ValueEncodings[bitNum]
+	if encoding.isEBCDIC() {
+		start := len(m.valueBuffer)
+		m.valueBuffer = append(m.valueBuffer, src...)
+		value := m.valueBuffer[start:len(m.valueBuffer):len(m.valueBuffer)]
+		ebcdicToASCII(value, value, encoding)
+		return value, nil
+	}
+	if encoding != EncodingBCD {
+		return src, nil
+	}
+
+	config := &m.packager.IsoPackagerConfig[bitNum]
+	skip := 0
+	if length%2 == 1 && config.Padding != PaddingRight {
+		skip = 1
+	}
+	// track data separators are packed as 0xD, decode them back to '='
+	track := config.Type == BitTypeZ
+
+	start := len(m.valueBuffer)
+	for i, b := range src {
+		for j, n := range [2]byte{b >> 4, b & 0x0F} {
+			pos := i*2 + j
+			if pos < skip || pos-skip >= length {
+				continue
+			}
+			c := hexTable[n][1]
+			if track && n == 0x0D {
+				c = '='
+			}
+			m.valueBuffer = append(m.valueBuffer, c)
+		}
+	}
+
+	return m.valueBuffer[start:len(m.valueBuffer):len(m.valueBuffer)], nil
+}
+
+// hexNibble converts a hex digit character to its nibble value.
+// '=' is accepted as the track 2 field separator (0xD).
+func hexNibble(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c == '=':
+		return 0x0D, true
+	default:
+		return 0, false
+	}
+}
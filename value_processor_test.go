@@ -0,0 +1,53 @@
+package iso8583
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBCDValueRoundTrip(t *testing.T) {
+	packager := newTestPackager(t, "",
+		`"3": {"type": "n", "length": {"type": "FIXED", "max": 6}, "encoding": "bcd"}`,
+		`"4": {"type": "n", "length": {"type": "FIXED", "max": 12}, "encoding": "bcd"}`,
+		`"22": {"type": "n", "length": {"type": "FIXED", "max": 3}, "encoding": "bcd"}`,
+		`"23": {"type": "n", "length": {"type": "FIXED", "max": 3}, "encoding": "bcd", "padding": "RIGHT", "filler": "F"}`,
+		`"32": {"type": "n", "length": {"type": "LLVAR", "max": 11, "encoding": "bcd"}, "encoding": "bcd"}`,
+		`"35": {"type": "z", "length": {"type": "LLVAR", "max": 37, "encoding": "bcd"}, "encoding": "bcd"}`,
+	)
+
+	tests := map[int]string{
+		3:  "000000",
+		4:  "000000001000",
+		22: "051",
+		23: "001",
+		32: "12345",
+		35: "4111111111111111=2512101123456789",
+	}
+
+	msg := NewMessage(packager)
+	msg.SetMtiString("0200")
+	for bit, value := range tests {
+		msg.SetString(bit, value)
+	}
+	b, err := msg.PackISO()
+	require.NoError(t, err)
+
+	got := NewMessage(packager)
+	require.NoError(t, got.Unpack(b))
+	for bit, value := range tests {
+		assert.Equal(t, value, got.GetString(bit), "bit %d", bit)
+	}
+	assert.NoError(t, got.ValidateBitType(), "track 2 keeps its separator")
+}
+
+func TestBCDValueInvalidDigit(t *testing.T) {
+	packager := newTestPackager(t, "", `"3": {"type": "n", "length": {"type": "FIXED", "max": 6}, "encoding": "bcd"}`)
+
+	msg := NewMessage(packager)
+	msg.SetMtiString("0200")
+	msg.SetString(3, "00000G")
+	_, err := msg.PackISO()
+	assert.Error(t, err)
+}